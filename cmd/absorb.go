@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/madhermit/rift/internal/absorb"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var absorbCmd = &cobra.Command{
+	Use:   "absorb",
+	Short: "Route staged hunks into the commits they fix",
+	Long:  "For each staged hunk, blame the lines it changes and, when they were all last touched by the same commit since the merge-base with the upstream, record the hunk as a fixup! of that commit. Hunks without a single unpushed owner stay staged. Use --dry-run to only show the assignments.",
+	Args:  cobra.NoArgs,
+	RunE:  runAbsorb,
+}
+
+func init() {
+	absorbCmd.Flags().String("upstream", "", "Branch to measure unpushed commits against (default: the current branch's upstream)")
+	absorbCmd.Flags().Bool("dry-run", false, "Show hunk assignments without creating commits")
+	absorbCmd.Flags().Bool("autosquash", false, "Rebase to fold the fixups into their targets immediately")
+	rootCmd.AddCommand(absorbCmd)
+}
+
+// absorbResult is the --json payload: every staged hunk's assignment and the
+// fixup commits created for them.
+type absorbResult struct {
+	Assignments  []absorb.Assignment `json:"assignments"`
+	Fixups       []git.CommitInfo    `json:"fixups"`
+	Autosquashed bool                `json:"autosquashed"`
+}
+
+func runAbsorb(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+	upstream, _ := cmd.Flags().GetString("upstream")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	autosquash, _ := cmd.Flags().GetBool("autosquash")
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	if upstream == "" {
+		if upstream, err = repo.Upstream(); err != nil {
+			return fmt.Errorf("%w (pass --upstream)", err)
+		}
+	}
+	base, err := repo.MergeBase(upstream)
+	if err != nil {
+		return err
+	}
+
+	plan, err := absorb.Plan(repo, base)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		return nothingToDo("nothing staged to absorb")
+	}
+
+	res := absorbResult{Assignments: plan, Fixups: []git.CommitInfo{}}
+	if !dryRun {
+		if res.Fixups, err = absorb.Apply(repo, plan); err != nil {
+			return err
+		}
+		if autosquash && len(res.Fixups) > 0 {
+			if err := repo.Autosquash(base); err != nil {
+				return partialFailure(fmt.Errorf("%d fixup commits created, but %w", len(res.Fixups), err))
+			}
+			res.Autosquashed = true
+		}
+	}
+
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, res)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, []absorbResult{res})
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), plan)
+	}
+
+	lines := make([]string, 0, len(plan)+1)
+	left := 0
+	for _, a := range plan {
+		if a.Assigned() {
+			lines = append(lines, fmt.Sprintf("%s %s -> %s %s", a.Path, a.Hunk, a.Commit, a.Message))
+		} else {
+			left++
+			lines = append(lines, fmt.Sprintf("%s %s -> staged (%s)", a.Path, a.Hunk, a.Reason))
+		}
+	}
+	if mode == output.Interactive {
+		verb := "created"
+		if dryRun {
+			verb = "would create"
+		}
+		lines = append(lines, fmt.Sprintf("%s %d fixup commits, %d hunks left staged", verb, countTargets(plan), left))
+	}
+	return output.WritePlain(os.Stdout, lines)
+}
+
+func countTargets(plan []absorb.Assignment) int {
+	seen := map[string]bool{}
+	for _, a := range plan {
+		if a.Assigned() {
+			seen[a.Commit] = true
+		}
+	}
+	return len(seen)
+}
@@ -29,6 +29,7 @@ func runBranch(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	defer repo.Close()
 
 	branches, err := repo.ListBranches()
 	if err != nil {
@@ -38,6 +39,10 @@ func runBranch(cmd *cobra.Command, args []string) error {
 	switch mode {
 	case output.JSON:
 		return output.WriteJSON(os.Stdout, branches)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, branches)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), branches)
 	case output.Print:
 		lines := make([]string, len(branches))
 		for i, b := range branches {
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/madhermit/rift/internal/cache"
+	"github.com/madhermit/rift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the diff cache",
+	Long: `Rendered diffs are cached under $XDG_CACHE_HOME/rift (~/.cache/rift by
+default), keyed by the content on each side, the difftastic version, width
+and color, so going back to a commit or file is instant. The least recently
+used entries are evicted once the cache passes 256 MiB.`,
+	Args: cobra.NoArgs,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the cache's location, entries and size",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCache(cmd, (*cache.Cache).Stats, func(s cache.Stats) string {
+			return fmt.Sprintf("%d entries, %s of %s, in %s", s.Entries, mib(s.Bytes), mib(s.MaxBytes), s.Dir)
+		})
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached diff",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCache(cmd, (*cache.Cache).Clear, func(s cache.Stats) string {
+			return fmt.Sprintf("Cleared %d entries (%s) from %s", s.Entries, mib(s.Bytes), s.Dir)
+		})
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// runCache runs op on the cache and reports the stats it returns: the
+// cache's for stats, what was removed for clear. line is the --print form.
+func runCache(cmd *cobra.Command, op func(*cache.Cache) (cache.Stats, error), line func(cache.Stats) string) error {
+	mode := output.Detect(cmd)
+
+	c, err := cache.Open()
+	if err != nil {
+		return err
+	}
+	s, err := op(c)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, s)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, []cache.Stats{s})
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), []cache.Stats{s})
+	}
+	return output.WritePlain(os.Stdout, []string{line(s)})
+}
+
+func mib(bytes int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	commitui "github.com/madhermit/rift/internal/tui/commit"
+	"github.com/spf13/cobra"
+)
+
+var commitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Commit composer with staged diff preview",
+	Long:  "Write a commit message next to the staged files and their syntax-aware diff, then commit through git so hooks run. With --print or --json, commit non-interactively using -m and report the new commit.",
+	Args:  cobra.NoArgs,
+	RunE:  runCommit,
+}
+
+func init() {
+	commitCmd.Flags().StringP("message", "m", "", "Commit message (prefills the editor in the TUI)")
+	commitCmd.Flags().Bool("amend", false, "Amend HEAD instead of creating a new commit")
+	rootCmd.AddCommand(commitCmd)
+}
+
+func runCommit(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+	message, _ := cmd.Flags().GetString("message")
+	amend, _ := cmd.Flags().GetBool("amend")
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	files, err := listChangedFiles(repo, true, false, "", "")
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 && !amend {
+		return nothingToDo("nothing staged to commit")
+	}
+
+	switch mode {
+	case output.JSON, output.NDJSON, output.Template, output.Print:
+		if message == "" && !amend {
+			return usageError(errors.New("commit message required: pass -m with --print, --json, --ndjson or --format"))
+		}
+		info, err := repo.Commit(message, amend)
+		if err != nil {
+			return err
+		}
+		switch mode {
+		case output.JSON:
+			return output.WriteJSON(os.Stdout, info)
+		case output.NDJSON:
+			return output.WriteNDJSON(os.Stdout, []git.CommitInfo{info})
+		case output.Template:
+			return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), []git.CommitInfo{info})
+		}
+		return output.WritePlain(os.Stdout, []string{info.Hash + " " + info.Message})
+	default:
+		headMessage, _ := repo.HeadMessage()
+		engine := diff.NewEngine()
+		m := commitui.New(repo, engine, files, message, amend, headMessage)
+		result, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+		if err != nil {
+			return err
+		}
+
+		final, ok := result.(commitui.Model)
+		if !ok || !final.Committed() {
+			return nil
+		}
+		info, err := repo.Commit(final.Message(), final.Amend())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[%s] %s\n", info.Hash, info.Message)
+		return nil
+	}
+}
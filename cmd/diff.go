@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"os"
+	"slices"
 	"sort"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,7 +18,7 @@ import (
 )
 
 var diffCmd = &cobra.Command{
-	Use:   "diff [flags] [commit [commit]] [-- path...]",
+	Use:   "diff [flags] [commit [commit] | commit..commit | commit...commit] [-- path...]",
 	Short: "Browse changes with syntax-aware diffs",
 	Long:  "Show file changes with syntax-aware diffing powered by difftastic. Supports fuzzy file filtering and split-pane browsing.",
 	RunE:  runDiff,
@@ -24,6 +27,8 @@ var diffCmd = &cobra.Command{
 func init() {
 	diffCmd.Flags().Bool("staged", false, "Show staged changes")
 	diffCmd.Flags().Bool("name-only", false, "Only show changed file names")
+	diffCmd.Flags().Bool("untracked", true, "List untracked files as Added with unstaged changes")
+	diffCmd.Flags().Bool("no-untracked", false, "Leave untracked files out")
 	rootCmd.AddCommand(diffCmd)
 }
 
@@ -38,24 +43,36 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	mode := output.Detect(cmd)
 	staged, _ := cmd.Flags().GetBool("staged")
 	nameOnly, _ := cmd.Flags().GetBool("name-only")
+	untracked, _ := cmd.Flags().GetBool("untracked")
+	noUntracked, _ := cmd.Flags().GetBool("no-untracked")
+	if noUntracked && cmd.Flags().Changed("untracked") {
+		return usageError(errors.New("--untracked and --no-untracked can't be combined"))
+	}
+	untracked = untracked && !noUntracked
 	refArgs, pathArgs := splitAtDash(cmd, args)
 
 	repo, err := git.OpenRepo()
 	if err != nil {
 		return err
 	}
+	defer repo.Close()
 
 	engine := diff.NewEngine()
-	base, target, err := git.DiffTargets(refArgs)
+	rng, err := diffRange(repo, refArgs)
 	if err != nil {
 		return err
 	}
+	base, target := rng.From(), rng.Target
 
-	files, err := listChangedFiles(repo, staged, base, target)
+	spec, err := repo.Pathspec(pathArgs)
+	if err != nil {
+		return usageError(err)
+	}
+	files, err := listChangedFiles(repo, staged, untracked, base, target)
 	if err != nil {
 		return err
 	}
-	files = git.FilterByPaths(files, pathArgs)
+	files = git.FilterByPaths(files, spec)
 
 	if nameOnly {
 		return printFileNames(files)
@@ -63,17 +80,53 @@ func runDiff(cmd *cobra.Command, args []string) error {
 
 	switch mode {
 	case output.JSON:
-		return output.WriteJSON(os.Stdout, files)
+		diffs := slices.AppendSeq([]fileDiffJSON{}, structuredDiffs(engine, repo, files, staged, base, target))
+		if err := output.WriteJSON(os.Stdout, diffs); err != nil {
+			return err
+		}
+		failed := 0
+		for _, d := range diffs {
+			if d.Error != nil {
+				failed++
+			}
+		}
+		return diffFailures(failed, len(files))
+	case output.NDJSON:
+		failed := 0
+		for d := range structuredDiffs(engine, repo, files, staged, base, target) {
+			if err := output.WriteNDJSON(os.Stdout, []fileDiffJSON{d}); err != nil {
+				return err
+			}
+			if d.Error != nil {
+				failed++
+			}
+		}
+		return diffFailures(failed, len(files))
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), files)
 	case output.Print:
 		return printDiffs(engine, repo, files, staged, base, target)
 	default:
-		m := diffui.New(repo, engine, files, staged, base, target)
+		m := diffui.New(repo, engine, files, staged, untracked, rng)
 		_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
 		return err
 	}
 }
 
-func listChangedFiles(repo *git.Repo, staged bool, base, target string) ([]git.ChangedFile, error) {
+// diffRange reads rift diff's revision arguments and resolves them against
+// repo.
+func diffRange(repo *git.Repo, args []string) (git.DiffRange, error) {
+	rng, err := git.DiffTargets(args)
+	if err != nil {
+		return git.DiffRange{}, usageError(err)
+	}
+	return repo.ResolveDiffRange(rng)
+}
+
+// listChangedFiles lists what rift diff shows: the changes between two
+// commits, or the staged or unstaged ones, untracked files included with
+// the latter if untracked is set.
+func listChangedFiles(repo *git.Repo, staged, untracked bool, base, target string) ([]git.ChangedFile, error) {
 	var (
 		files []git.ChangedFile
 		err   error
@@ -81,7 +134,7 @@ func listChangedFiles(repo *git.Repo, staged bool, base, target string) ([]git.C
 	if target != "" {
 		files, err = repo.DiffBetweenCommits(base, target)
 	} else {
-		files, err = repo.ChangedFiles(staged)
+		files, err = repo.ChangedFiles(staged, untracked)
 	}
 	if err != nil {
 		return nil, err
@@ -95,6 +148,51 @@ func listChangedFiles(repo *git.Repo, staged bool, base, target string) ([]git.C
 	return files, nil
 }
 
+// fileDiffJSON is the --json payload for a single file: its change status
+// plus the structural diff, or why one couldn't be computed.
+type fileDiffJSON struct {
+	git.ChangedFile
+	Language string        `json:"language,omitempty"`
+	Chunks   []diff.Chunk  `json:"chunks"`
+	Error    *output.Error `json:"error,omitempty"`
+}
+
+// diffFailures turns files whose diff failed into an exit status. Each
+// failure has already been reported next to its file.
+func diffFailures(failed, total int) error {
+	if failed == 0 {
+		return nil
+	}
+	return reportedPartialFailure(fmt.Errorf("diff failed for %d of %d files", failed, total))
+}
+
+// structuredDiffs computes each file's payload as the loop asks for it, so
+// --ndjson can write one before computing the next.
+func structuredDiffs(engine diff.Engine, repo *git.Repo, files []git.ChangedFile, staged bool, base, target string) iter.Seq[fileDiffJSON] {
+	return func(yield func(fileDiffJSON) bool) {
+		ctx := context.Background()
+		for _, f := range files {
+			result := fileDiffJSON{ChangedFile: f, Chunks: []diff.Chunk{}}
+			sd, err := engine.DiffStructured(ctx, repo.Root(), f.Path, diff.DiffOpts{
+				OldPath:   f.OldPath,
+				Untracked: f.Untracked,
+				Staged:    staged,
+				Base:      base,
+				Target:    target,
+			})
+			if err != nil {
+				result.Error = &output.Error{Code: codeDiffFailed, Message: err.Error(), Path: f.Path}
+			} else {
+				result.Language = sd.Language
+				result.Chunks = sd.Chunks
+			}
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
 func printFileNames(files []git.ChangedFile) error {
 	lines := make([]string, len(files))
 	for i, f := range files {
@@ -105,20 +203,24 @@ func printFileNames(files []git.ChangedFile) error {
 
 func printDiffs(engine diff.Engine, repo *git.Repo, files []git.ChangedFile, staged bool, base, target string) error {
 	ctx := context.Background()
+	failed := 0
 	for _, f := range files {
 		out, err := engine.Diff(ctx, repo.Root(), f.Path, diff.DiffOpts{
-			Staged: staged,
-			Base:   base,
-			Target: target,
-			Color:  false,
+			OldPath:   f.OldPath,
+			Untracked: f.Untracked,
+			Staged:    staged,
+			Base:      base,
+			Target:    target,
+			Color:     false,
 		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "warning: diff failed for %s: %v\n", f.Path, err)
+			failed++
 			continue
 		}
 		if out != "" {
 			fmt.Fprint(os.Stdout, out)
 		}
 	}
-	return nil
+	return diffFailures(failed, len(files))
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var discardCmd = &cobra.Command{
+	Use:   "discard [--restore [id]]",
+	Short: "List and restore discarded changes",
+	Long:  "Changes discarded from rift stage or rift diff are saved as patches under .git/rift/discarded/. List them, or re-apply one with --restore (the most recent if no id is given).",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runDiscard,
+}
+
+func init() {
+	discardCmd.Flags().Bool("restore", false, "Re-apply a discarded patch to the worktree")
+	rootCmd.AddCommand(discardCmd)
+}
+
+func runDiscard(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+	restore, _ := cmd.Flags().GetBool("restore")
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	patches, err := repo.ListDiscarded()
+	if err != nil {
+		return err
+	}
+
+	if restore {
+		return restoreDiscarded(repo, patches, args)
+	}
+	if len(args) > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q (did you mean --restore?)", args[0]))
+	}
+
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, patches)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, patches)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), patches)
+	default:
+		if len(patches) == 0 && mode == output.Interactive {
+			fmt.Println("No discarded changes.")
+			return nil
+		}
+		lines := make([]string, len(patches))
+		for i, p := range patches {
+			lines[i] = fmt.Sprintf("%s  %s  %s", p.ID, p.Date, p.Path)
+		}
+		return output.WritePlain(os.Stdout, lines)
+	}
+}
+
+func restoreDiscarded(repo *git.Repo, patches []git.DiscardedPatch, args []string) error {
+	if len(patches) == 0 {
+		return nothingToDo("no discarded changes to restore")
+	}
+	target := patches[0]
+	if len(args) > 0 {
+		found := false
+		for _, p := range patches {
+			if p.ID == args[0] {
+				target, found = p, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no discarded patch with id %q", args[0])
+		}
+	}
+	if err := repo.RestoreDiscarded(target.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "restored %s\n", target.Path)
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes are part of rift's scripting interface and documented in the
+// README. Anything not classified below exits 1.
+const (
+	exitError       = 1
+	exitUsage       = 2
+	exitNotRepo     = 3
+	exitBadRef      = 4
+	exitNothingToDo = 5
+	exitPartial     = 6
+	exitStale       = 7
+)
+
+// Error codes for --json/--ndjson error objects. codeDiffFailed only appears
+// on individual files inside diff output.
+const (
+	codeError       = "error"
+	codeUsage       = "usage"
+	codeNotRepo     = "not_a_repo"
+	codeBadRef      = "bad_ref"
+	codeNothingToDo = "nothing_to_do"
+	codePartial     = "partial_failure"
+	codeStaleHunk   = "stale_hunk"
+	codeDiffFailed  = "diff_failed"
+)
+
+// codedError gives an error a code and exit status where the git package's
+// error types don't already imply one.
+type codedError struct {
+	code string
+	exit int
+	err  error
+
+	// reported means the output already described the failure (e.g. per
+	// file), so only the exit status is left to set.
+	reported bool
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+func usageError(err error) error {
+	return &codedError{code: codeUsage, exit: exitUsage, err: err}
+}
+
+// nothingToDo reports that the command had no work, e.g. nothing staged.
+func nothingToDo(msg string) error {
+	return &codedError{code: codeNothingToDo, exit: exitNothingToDo, err: errors.New(msg)}
+}
+
+// partialFailure reports that some of the work was done before err.
+func partialFailure(err error) error {
+	return &codedError{code: codePartial, exit: exitPartial, err: err}
+}
+
+// staleHunk reports hunk IDs that no longer name a hunk, because the file
+// changed since they were listed.
+func staleHunk(err error) error {
+	return &codedError{code: codeStaleHunk, exit: exitStale, err: err}
+}
+
+// reportedPartialFailure is partialFailure for failures the output already
+// described.
+func reportedPartialFailure(err error) error {
+	return &codedError{code: codePartial, exit: exitPartial, err: err, reported: true}
+}
+
+// classify maps err to its structured form and exit status.
+func classify(err error) (e output.Error, exit int, reported bool) {
+	e = output.Error{Code: codeError, Message: err.Error()}
+
+	var coded *codedError
+	var refErr *git.RefError
+	switch {
+	case errors.As(err, &coded):
+		e.Code = coded.code
+		return e, coded.exit, coded.reported
+	case errors.Is(err, git.ErrNotRepository):
+		e.Code = codeNotRepo
+		return e, exitNotRepo, false
+	case errors.As(err, &refErr):
+		e.Code = codeBadRef
+		e.Ref = refErr.Ref
+		return e, exitBadRef, false
+	}
+	return e, exitError, false
+}
+
+// wrapArgErrors marks argument-count errors from every command as usage
+// errors; cobra reports flag errors through SetFlagErrorFunc instead.
+func wrapArgErrors(c *cobra.Command) {
+	if args := c.Args; args != nil {
+		c.Args = func(cmd *cobra.Command, a []string) error {
+			if err := args(cmd, a); err != nil {
+				return usageError(err)
+			}
+			return nil
+		}
+	}
+	for _, sub := range c.Commands() {
+		wrapArgErrors(sub)
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	logui "github.com/madhermit/rift/internal/tui/log"
+	"github.com/spf13/cobra"
+)
+
+var fixupCmd = &cobra.Command{
+	Use:   "fixup [commit]",
+	Short: "Fold staged changes into an earlier commit",
+	Long:  "Pick one of the commits since the merge-base with the upstream and record the staged changes as a fixup! commit for it. With --autosquash, rebase right away to fold it in; a rebase that conflicts is aborted and the fixup commit is left on HEAD. With --print or --json and no commit, list the candidate commits.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runFixup,
+}
+
+func init() {
+	fixupCmd.Flags().String("upstream", "", "Branch to measure unpushed commits against (default: the current branch's upstream)")
+	fixupCmd.Flags().Bool("autosquash", false, "Rebase to fold the fixup into its target immediately")
+	rootCmd.AddCommand(fixupCmd)
+}
+
+// fixupResult describes a created fixup commit to --json, --ndjson and
+// --format.
+type fixupResult struct {
+	Commit       git.CommitInfo `json:"commit"`
+	Target       string         `json:"target"`
+	Autosquashed bool           `json:"autosquashed"`
+}
+
+func runFixup(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+	upstream, _ := cmd.Flags().GetString("upstream")
+	autosquash, _ := cmd.Flags().GetBool("autosquash")
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	if upstream == "" {
+		if upstream, err = repo.Upstream(); err != nil {
+			return fmt.Errorf("%w (pass --upstream)", err)
+		}
+	}
+	base, err := repo.MergeBase(upstream)
+	if err != nil {
+		return err
+	}
+	commits, err := repo.CommitsSince(base)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 && mode != output.Interactive {
+		switch mode {
+		case output.JSON:
+			return output.WriteJSON(os.Stdout, commits)
+		case output.NDJSON:
+			return output.WriteNDJSON(os.Stdout, commits)
+		case output.Template:
+			return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), commits)
+		}
+		lines := make([]string, len(commits))
+		for i, c := range commits {
+			lines[i] = fmt.Sprintf("%s %s", c.Hash, c.Message)
+		}
+		return output.WritePlain(os.Stdout, lines)
+	}
+
+	staged, err := listChangedFiles(repo, true, false, "", "")
+	if err != nil {
+		return err
+	}
+	if len(staged) == 0 {
+		return nothingToDo("nothing staged to commit")
+	}
+
+	var target string
+	if len(args) > 0 {
+		resolved, err := repo.Log(args[0], 1, git.Pathspec{})
+		if err != nil {
+			return err
+		}
+		if len(resolved) == 0 {
+			return &git.RefError{Ref: args[0]}
+		}
+		target = resolved[0].Hash
+		// A fixup! for a commit the autosquash rebase doesn't replay would
+		// never be folded in.
+		if !slices.ContainsFunc(commits, func(c git.CommitInfo) bool { return c.Hash == target }) {
+			return usageError(fmt.Errorf("%s is not one of the commits since %s", args[0], upstream))
+		}
+	} else {
+		if len(commits) == 0 {
+			fmt.Printf("No commits since %s.\n", upstream)
+			return nil
+		}
+		m := logui.NewPicker(repo, diff.NewEngine(), commits, "rift fixup")
+		result, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+		if err != nil {
+			return err
+		}
+		final, ok := result.(logui.Model)
+		if !ok {
+			return nil
+		}
+		picked, ok := final.Picked()
+		if !ok {
+			return nil
+		}
+		target = picked.Hash
+	}
+
+	info, err := repo.Fixup(target)
+	if err != nil {
+		return err
+	}
+	res := fixupResult{Commit: info, Target: target}
+	if autosquash {
+		if err := repo.Autosquash(base); err != nil {
+			return partialFailure(fmt.Errorf("fixup %s created, but %w", info.Hash, err))
+		}
+		res.Autosquashed = true
+	}
+
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, res)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, []fixupResult{res})
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), []fixupResult{res})
+	case output.Print:
+		return output.WritePlain(os.Stdout, []string{info.Hash + " " + info.Message})
+	default:
+		fmt.Printf("[%s] %s\n", info.Hash, info.Message)
+		if res.Autosquashed {
+			fmt.Printf("autosquashed onto %s\n", upstream)
+		}
+		return nil
+	}
+}
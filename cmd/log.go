@@ -35,16 +35,28 @@ func runLog(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	defer repo.Close()
+
+	spec, err := repo.Pathspec(pathArgs)
+	if err != nil {
+		return usageError(err)
+	}
 
 	var commits []git.CommitInfo
 	if all {
-		commits, err = repo.LogAll(maxCount, pathArgs)
+		if mode == output.NDJSON {
+			return output.StreamNDJSON(os.Stdout, repo.LogAllSeq(maxCount, spec))
+		}
+		commits, err = repo.LogAll(maxCount, spec)
 	} else {
 		ref := "HEAD"
 		if len(refArgs) > 0 {
 			ref = refArgs[0]
 		}
-		commits, err = repo.Log(ref, maxCount, pathArgs)
+		if mode == output.NDJSON {
+			return output.StreamNDJSON(os.Stdout, repo.LogSeq(ref, maxCount, spec))
+		}
+		commits, err = repo.Log(ref, maxCount, spec)
 	}
 	if err != nil {
 		return err
@@ -53,6 +65,8 @@ func runLog(cmd *cobra.Command, args []string) error {
 	switch mode {
 	case output.JSON:
 		return output.WriteJSON(os.Stdout, commits)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), commits)
 	case output.Print:
 		lines := make([]string, len(commits))
 		for i, c := range commits {
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	rebaseui "github.com/madhermit/rift/internal/tui/rebase"
+	"github.com/spf13/cobra"
+)
+
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase [upstream]",
+	Short: "Interactive rebase editor with commit preview",
+	Long:  "Edit the todo list for rebasing onto upstream (default: the current branch's upstream) — pick, reword, edit, squash, fixup or drop each commit and reorder them — with a syntax-aware preview of every commit, then run git rebase -i with it. With --print or --json, show the default todo instead.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runRebase,
+}
+
+func init() {
+	rootCmd.AddCommand(rebaseCmd)
+}
+
+func runRebase(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	var upstream string
+	if len(args) > 0 {
+		upstream = args[0]
+	} else if upstream, err = repo.Upstream(); err != nil {
+		return fmt.Errorf("%w (pass one as an argument)", err)
+	}
+
+	steps, err := repo.RebaseTodo(upstream)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, steps)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, steps)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), steps)
+	case output.Print:
+		lines := make([]string, len(steps))
+		for i, s := range steps {
+			lines[i] = fmt.Sprintf("%s %s %s", s.Action, s.Hash, s.Message)
+		}
+		return output.WritePlain(os.Stdout, lines)
+	default:
+		if len(steps) == 0 {
+			fmt.Printf("Nothing to rebase onto %s.\n", upstream)
+			return nil
+		}
+		if repo.RebaseInProgress() {
+			return errors.New("a rebase is already in progress (git rebase --continue or --abort)")
+		}
+		if dirty, err := repo.HasTrackedChanges(); err != nil {
+			return err
+		} else if dirty {
+			return errors.New("cannot rebase with uncommitted changes; commit or stash them first")
+		}
+
+		m := rebaseui.New(repo, diff.NewEngine(), upstream, steps)
+		result, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+		if err != nil {
+			return err
+		}
+		final, ok := result.(rebaseui.Model)
+		if !ok || !final.Confirmed() {
+			return nil
+		}
+		if err := repo.Rebase(upstream, final.Steps()); err != nil {
+			return err
+		}
+		if repo.RebaseInProgress() {
+			fmt.Println("Rebase stopped for editing; run git rebase --continue when done.")
+		}
+		return nil
+	}
+}
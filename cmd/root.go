@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/madhermit/rift/internal/output"
@@ -21,11 +24,65 @@ func init() {
 	rootCmd.RunE = runRoot
 	rootCmd.PersistentFlags().Bool("print", false, "Output in plain text (non-interactive)")
 	rootCmd.PersistentFlags().Bool("json", false, "Output in JSON format")
-	rootCmd.PersistentFlags().String("format", "", "Output format template")
+	rootCmd.PersistentFlags().Bool("ndjson", false, "Output one JSON object per line, streamed as produced")
+	rootCmd.PersistentFlags().String("format", "", "Render each item through a Go template, e.g. '{{.Hash}} {{.Message}}'")
 }
 
-func Execute() error {
-	return rootCmd.Execute()
+// Execute runs the command line and returns the process exit status. In
+// --json and --ndjson modes a failure is written to stdout as a structured
+// error object; otherwise its message goes to stderr.
+func Execute() int {
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return usageError(err)
+	})
+	wrapArgErrors(rootCmd)
+
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return 0
+	}
+	e, exit, reported := classify(err)
+	if reported {
+		return exit
+	}
+	if mode := errorMode(cmd); mode == output.JSON || mode == output.NDJSON {
+		output.WriteError(os.Stdout, mode, e)
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return exit
+}
+
+// errorMode is the output mode to report a failure in. A bad flag stops
+// flag parsing, so --json or --ndjson after it is looked for by hand.
+func errorMode(cmd *cobra.Command) output.Mode {
+	mode := output.Detect(cmd)
+	if mode != output.JSON && mode != output.NDJSON {
+		switch {
+		case boolArg("ndjson"):
+			return output.NDJSON
+		case boolArg("json"):
+			return output.JSON
+		}
+	}
+	return mode
+}
+
+// boolArg reports whether os.Args sets the boolean flag name, as --name or
+// --name=value, the last setting winning as it does when flags parse.
+func boolArg(name string) bool {
+	set := false
+	for _, arg := range os.Args[1:] {
+		if arg == "--" {
+			break
+		}
+		if arg == "--"+name {
+			set = true
+		} else if v, ok := strings.CutPrefix(arg, "--"+name+"="); ok {
+			set, _ = strconv.ParseBool(v)
+		}
+	}
+	return set
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/madhermit/rift/internal/cache"
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// payload is the --json output of a command, described for rift schema.
+type payload struct {
+	command     string
+	v           any
+	description string
+}
+
+// payloads lists every command's --json output type. Adding or changing a
+// field in one of these means bumping output.SchemaVersion.
+var payloads = []payload{
+	{"absorb", absorbResult{}, "The hunks planned for absorption and the fixup commits created for them."},
+	{"branch", []git.BranchInfo{}, ""},
+	{"cache", cache.Stats{}, "The cache's location and size; rift cache clear reports what it removed in the same form."},
+	{"commit", git.CommitInfo{}, "The commit created."},
+	{"diff", []fileDiffJSON{}, "One entry per changed file; with --ndjson, one per line."},
+	{"discard", []git.DiscardedPatch{}, "Saved patches from discarded changes."},
+	{"fixup", fixupResult{}, "The fixup commit created. Without a commit argument, fixup lists candidate commits in the log schema instead."},
+	{"hunks", []diff.HunkRef{}, "Every staged and unstaged hunk; rift stage --hunk and rift unstage --hunk print the hunks they applied in the same form."},
+	{"log", []git.CommitInfo{}, ""},
+	{"rebase", []git.RebaseStep{}, "The default todo list for rebasing onto upstream."},
+	{"stage", []git.StatusFile{}, ""},
+	{"stash", []git.StashEntry{}, ""},
+	{"worktree", []git.WorktreeInfo{}, "worktree add, remove and switch print a single worktree in the items schema."},
+	{"error", output.ErrorPayload{}, "Written instead of the command's output when it fails in --json or --ndjson mode."},
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [command]",
+	Short: "Print the JSON Schema of a command's --json output",
+	Long: `Print a JSON Schema (draft 2020-12) for the --json output of command, or
+for every command keyed by name. Each top-level object rift writes carries
+"schema_version", which changes whenever a payload does. For list commands,
+each --ndjson line matches the schema's "items".`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: payloadCommands(),
+	RunE:      runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func payloadCommands() []string {
+	names := make([]string, len(payloads))
+	for i, p := range payloads {
+		names[i] = p.command
+	}
+	return names
+}
+
+func (p payload) schema() map[string]any {
+	title := "rift " + p.command + " --json"
+	switch p.command {
+	case "cache":
+		title = "rift cache stats --json"
+	case "hunks":
+		title = "rift stage --list-hunks --json"
+	case "error":
+		title = "rift error"
+	}
+	return output.Schema(title, p.description, p.v)
+}
+
+// Schemas are JSON whatever the output mode: a pipe would otherwise select
+// --print, and there's no plain-text form of a schema. They're written
+// without a schema_version of their own, which would be a stray keyword to
+// a validator; the version is the const inside each one.
+func runSchema(cmd *cobra.Command, args []string) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if len(args) == 0 {
+		all := make(map[string]any, len(payloads))
+		for _, p := range payloads {
+			all[p.command] = p.schema()
+		}
+		return enc.Encode(all)
+	}
+	for _, p := range payloads {
+		if p.command == args[0] {
+			return enc.Encode(p.schema())
+		}
+	}
+	return usageError(fmt.Errorf("no schema for %q (one of %s)", args[0], strings.Join(payloadCommands(), ", ")))
+}
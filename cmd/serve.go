@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	"github.com/madhermit/rift/internal/rpc"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Answer JSON-RPC and MCP requests on stdin",
+	Long: `Serve JSON-RPC 2.0 over stdio, one message per line, for agents and editors
+that would otherwise spawn rift per call. The repository and diff engine are
+opened once and kept warm between requests. The same methods are offered as
+Model Context Protocol tools, so rift serve can be registered as an MCP
+server. Methods: list-changes, structural-diff, log, branches, stashes,
+list-hunks, stage-hunk, unstage-hunk.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+type changesParams struct {
+	Staged      bool     `json:"staged,omitempty" doc:"Compare the index with HEAD instead of the worktree with the index"`
+	NoUntracked bool     `json:"no_untracked,omitempty" doc:"Leave out untracked files, otherwise listed as Added with unstaged changes"`
+	Revisions   []string `json:"revisions,omitempty" doc:"Up to two commits, or one A..B or A...B range, to compare as rift diff takes them"`
+	Paths       []string `json:"paths,omitempty" doc:"Only files these pathspecs select, relative to the repository root"`
+}
+
+type logParams struct {
+	Ref      string   `json:"ref,omitempty" doc:"Commit to start from (default HEAD)"`
+	All      bool     `json:"all,omitempty" doc:"Commits from all branches instead of ref"`
+	MaxCount int      `json:"max_count,omitempty" doc:"Maximum number of commits (default 200)"`
+	Paths    []string `json:"paths,omitempty" doc:"Only commits touching files these pathspecs select, relative to the repository root"`
+}
+
+type pathsParams struct {
+	Paths []string `json:"paths,omitempty" doc:"Only files these pathspecs select, relative to the repository root"`
+}
+
+type hunkParams struct {
+	IDs []string `json:"ids" doc:"Hunk IDs from list-hunks"`
+}
+
+type changesResult struct {
+	Files []git.ChangedFile `json:"files"`
+}
+
+type structuralDiffResult struct {
+	Files []fileDiffJSON `json:"files"`
+}
+
+type logResult struct {
+	Commits []git.CommitInfo `json:"commits"`
+}
+
+type branchesResult struct {
+	Branches []git.BranchInfo `json:"branches"`
+}
+
+type stashesResult struct {
+	Stashes []git.StashEntry `json:"stashes"`
+}
+
+type hunksResult struct {
+	Hunks []diff.HunkRef `json:"hunks"`
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	engine := diff.NewEngine()
+
+	s := rpc.NewServer("rift", Version)
+	s.Register(method("list-changes", "List changed files: unstaged, staged, or between commits.",
+		func(p changesParams) (changesResult, error) {
+			files, _, _, err := serveChangedFiles(repo, p)
+			return changesResult{files}, err
+		}))
+	s.Register(method("structural-diff", "Syntax-aware diff of each changed file: which lines changed and the changed spans within them.",
+		func(p changesParams) (structuralDiffResult, error) {
+			files, base, target, err := serveChangedFiles(repo, p)
+			if err != nil {
+				return structuralDiffResult{}, err
+			}
+			diffs := slices.AppendSeq([]fileDiffJSON{}, structuredDiffs(engine, repo, files, p.Staged, base, target))
+			return structuralDiffResult{diffs}, nil
+		}))
+	s.Register(method("log", "Commit history, newest first.",
+		func(p logParams) (logResult, error) {
+			if p.MaxCount == 0 {
+				p.MaxCount = 200
+			}
+			spec, err := servePathspec(p.Paths)
+			if err != nil {
+				return logResult{}, err
+			}
+			var commits []git.CommitInfo
+			if p.All {
+				commits, err = repo.LogAll(p.MaxCount, spec)
+			} else {
+				if p.Ref == "" {
+					p.Ref = "HEAD"
+				}
+				commits, err = repo.Log(p.Ref, p.MaxCount, spec)
+			}
+			return logResult{commits}, err
+		}))
+	s.Register(method("branches", "Local and remote branches.",
+		func(struct{}) (branchesResult, error) {
+			branches, err := repo.ListBranches()
+			return branchesResult{branches}, err
+		}))
+	s.Register(method("stashes", "Stash entries, newest first.",
+		func(struct{}) (stashesResult, error) {
+			stashes, err := repo.ListStashes()
+			return stashesResult{stashes}, err
+		}))
+	s.Register(method("list-hunks", "Every unstaged and staged hunk, with the ID stage-hunk and unstage-hunk take.",
+		func(p pathsParams) (hunksResult, error) {
+			spec, err := servePathspec(p.Paths)
+			if err != nil {
+				return hunksResult{}, err
+			}
+			hunks, err := listAllHunks(repo, spec)
+			return hunksResult{hunks}, err
+		}))
+	s.Register(method("stage-hunk", "Stage unstaged hunks by ID. Fails without staging anything if an ID is stale.",
+		func(p hunkParams) (hunksResult, error) {
+			hunks, err := applyHunks(repo, p.IDs, true)
+			return hunksResult{hunks}, err
+		}))
+	s.Register(method("unstage-hunk", "Unstage staged hunks by ID. Fails without unstaging anything if an ID is stale.",
+		func(p hunkParams) (hunksResult, error) {
+			hunks, err := applyHunks(repo, p.IDs, false)
+			return hunksResult{hunks}, err
+		}))
+
+	return s.Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
+
+// method adapts fn to an RPC method: results are stamped with the schema
+// version like every other JSON rift writes, and errors carry the same
+// error object --json prints, as the JSON-RPC error's data.
+func method[P, R any](name, description string, fn func(P) (R, error)) rpc.Method {
+	return rpc.NewMethod(name, description, func(_ context.Context, p P) (any, error) {
+		r, err := fn(p)
+		if err != nil {
+			return nil, rpcError(err)
+		}
+		return output.Stamp(r), nil
+	})
+}
+
+func rpcError(err error) error {
+	var rpcErr *rpc.Error
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+	e, _, _ := classify(err)
+	code := rpc.CodeServerError
+	if e.Code == codeUsage {
+		code = rpc.CodeInvalidParams
+	}
+	return &rpc.Error{Code: code, Message: e.Message, Data: e}
+}
+
+func serveChangedFiles(repo *git.Repo, p changesParams) (files []git.ChangedFile, base, target string, err error) {
+	rng, err := diffRange(repo, p.Revisions)
+	if err != nil {
+		return nil, "", "", err
+	}
+	base, target = rng.From(), rng.Target
+	spec, err := servePathspec(p.Paths)
+	if err != nil {
+		return nil, "", "", err
+	}
+	files, err = listChangedFiles(repo, p.Staged, !p.NoUntracked, base, target)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return git.FilterByPaths(files, spec), base, target, nil
+}
+
+// servePathspec reads paths from the repository root: the server's working
+// directory says nothing about where its client is.
+func servePathspec(paths []string) (git.Pathspec, error) {
+	spec, err := git.ParsePathspec("", paths)
+	if err != nil {
+		return git.Pathspec{}, usageError(err)
+	}
+	return spec, nil
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/madhermit/rift/internal/diff"
@@ -15,23 +19,54 @@ import (
 var stageCmd = &cobra.Command{
 	Use:   "stage [-- path...]",
 	Short: "Interactive staging with diff preview",
-	Long:  "Stage and unstage files and hunks with syntax-aware diff preview.",
-	RunE:  runStage,
+	Long: `Stage and unstage files and hunks with syntax-aware diff preview.
+
+Without the TUI, --list-hunks lists every staged and unstaged hunk with an
+ID, and --hunk stages the unstaged hunks with those IDs (rift unstage --hunk
+is the reverse). An ID hashes the hunk's file and lines, so it still works
+after other hunks are staged, and fails as stale once the hunk has changed.`,
+	RunE: runStage,
 }
 
 func init() {
+	stageCmd.Flags().Bool("list-hunks", false, "List staged and unstaged hunks with their IDs")
+	stageCmd.Flags().StringSlice("hunk", nil, "Stage the hunk with this ID (repeatable)")
 	rootCmd.AddCommand(stageCmd)
 }
 
 func runStage(cmd *cobra.Command, args []string) error {
 	mode := output.Detect(cmd)
+	listHunks, _ := cmd.Flags().GetBool("list-hunks")
+	ids, _ := cmd.Flags().GetStringSlice("hunk")
+	if listHunks && len(ids) > 0 {
+		return usageError(errors.New("--list-hunks and --hunk can't be combined"))
+	}
+	if len(ids) > 0 && len(args) > 0 {
+		return usageError(errors.New("--hunk names hunks by ID and takes no paths"))
+	}
 
 	repo, err := git.OpenRepo()
 	if err != nil {
 		return err
 	}
+	defer repo.Close()
+
+	spec, err := repo.Pathspec(args)
+	if err != nil {
+		return usageError(err)
+	}
+	if listHunks {
+		hunks, err := listAllHunks(repo, spec)
+		if err != nil {
+			return err
+		}
+		return writeHunks(cmd, mode, hunks, "")
+	}
+	if len(ids) > 0 {
+		return runHunks(cmd, mode, repo, ids, true)
+	}
 
-	files, err := repo.StatusFiles()
+	files, err := repo.StatusFiles(spec)
 	if err != nil {
 		return err
 	}
@@ -39,6 +74,10 @@ func runStage(cmd *cobra.Command, args []string) error {
 	switch mode {
 	case output.JSON:
 		return output.WriteJSON(os.Stdout, files)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, files)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), files)
 	case output.Print:
 		lines := make([]string, len(files))
 		for i, f := range files {
@@ -52,8 +91,148 @@ func runStage(cmd *cobra.Command, args []string) error {
 		}
 
 		engine := diff.NewEngine()
-		m := stageui.New(repo, engine, files)
+		m := stageui.New(repo, engine, files, spec)
 		_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
 		return err
 	}
 }
+
+// listAllHunks returns the hunks in files spec selects that rift stage
+// --hunk or rift unstage --hunk can take: unstaged hunks, including whole
+// untracked files, then staged ones, each in file order.
+func listAllHunks(repo *git.Repo, spec git.Pathspec) ([]diff.HunkRef, error) {
+	files, err := repo.StatusFiles(spec)
+	if err != nil {
+		return nil, err
+	}
+	var untracked []string
+	for _, f := range files {
+		if f.WorktreeStatus == "Untracked" {
+			untracked = append(untracked, f.Path)
+		}
+	}
+
+	unstaged, err := diff.ListHunks(repo.Root(), false)
+	if err != nil {
+		return nil, err
+	}
+	added, err := diff.NewFileHunks(repo.Root(), untracked)
+	if err != nil {
+		return nil, err
+	}
+	staged, err := diff.ListHunks(repo.Root(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	unstaged = append(unstaged, added...)
+	sort.SliceStable(unstaged, func(i, j int) bool { return unstaged[i].Path < unstaged[j].Path })
+	hunks := []diff.HunkRef{}
+	for _, h := range append(unstaged, staged...) {
+		if spec.Match(h.Path) {
+			hunks = append(hunks, h)
+		}
+	}
+	return hunks, nil
+}
+
+// applyHunks stages, or with stage unset unstages, the hunks with the given
+// IDs. Every ID is checked before anything is applied, so a stale one
+// changes nothing.
+func applyHunks(repo *git.Repo, ids []string, stage bool) ([]diff.HunkRef, error) {
+	if len(ids) == 0 {
+		return nil, usageError(errors.New("no hunk IDs given"))
+	}
+	hunks, err := listAllHunks(repo, git.Pathspec{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]diff.HunkRef, len(hunks))
+	for _, h := range hunks {
+		byID[h.ID] = h
+	}
+
+	selected := make([]diff.HunkRef, 0, len(ids))
+	var stale []string
+	for _, id := range ids {
+		h, ok := byID[id]
+		switch {
+		case !ok:
+			stale = append(stale, id)
+		case h.Staged == stage:
+			state := "unstaged"
+			if h.Staged {
+				state = "staged"
+			}
+			return nil, usageError(fmt.Errorf("hunk %s in %s is already %s", id, h.Path, state))
+		case !slices.ContainsFunc(selected, func(s diff.HunkRef) bool { return s.ID == id }):
+			selected = append(selected, h)
+		}
+	}
+	if len(stale) > 0 {
+		return nil, staleHunk(fmt.Errorf("no hunk with ID %s; the file changed since the hunks were listed (list them again)", strings.Join(stale, ", ")))
+	}
+
+	// Bottom-up within each file, so applying one hunk doesn't move the
+	// lines the next one expects.
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Path != selected[j].Path {
+			return selected[i].Path < selected[j].Path
+		}
+		return selected[i].OldStart > selected[j].OldStart
+	})
+	for i, h := range selected {
+		if stage {
+			err = repo.StageHunk(h.Patch())
+		} else {
+			err = repo.UnstageHunk(h.Patch())
+		}
+		if err != nil {
+			if i > 0 {
+				return nil, partialFailure(fmt.Errorf("%d of %d hunks applied, then hunk %s: %w", i, len(selected), h.ID, err))
+			}
+			return nil, err
+		}
+	}
+	return selected, nil
+}
+
+// runHunks is rift stage --hunk and rift unstage --hunk.
+func runHunks(cmd *cobra.Command, mode output.Mode, repo *git.Repo, ids []string, stage bool) error {
+	hunks, err := applyHunks(repo, ids, stage)
+	if err != nil {
+		return err
+	}
+	verb := "Unstaged"
+	if stage {
+		verb = "Staged"
+	}
+	return writeHunks(cmd, mode, hunks, verb)
+}
+
+// writeHunks writes hunks in the output mode. In plain text each is one
+// line: ID, staged or unstaged, path and range, or after applying them,
+// verb, ID, path and range.
+func writeHunks(cmd *cobra.Command, mode output.Mode, hunks []diff.HunkRef, verb string) error {
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, hunks)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, hunks)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), hunks)
+	}
+	lines := make([]string, len(hunks))
+	for i, h := range hunks {
+		if verb != "" {
+			lines[i] = fmt.Sprintf("%s %s %s %s", verb, h.ID, h.Path, h.Header())
+			continue
+		}
+		state := "unstaged"
+		if h.Staged {
+			state = "staged"
+		}
+		lines[i] = fmt.Sprintf("%s %s %s %s", h.ID, state, h.Path, h.Header())
+	}
+	return output.WritePlain(os.Stdout, lines)
+}
@@ -31,6 +31,7 @@ func runStash(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	defer repo.Close()
 
 	stashes, err := repo.ListStashes()
 	if err != nil {
@@ -40,6 +41,10 @@ func runStash(cmd *cobra.Command, args []string) error {
 	switch mode {
 	case output.JSON:
 		return output.WriteJSON(os.Stdout, stashes)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, stashes)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), stashes)
 	case output.Print:
 		lines := make([]string, len(stashes))
 		for i, s := range stashes {
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var unstageCmd = &cobra.Command{
+	Use:   "unstage --hunk <id>...",
+	Short: "Unstage hunks by ID",
+	Long:  "Unstage the staged hunks with the given IDs, as listed by rift stage --list-hunks. A stale ID fails without unstaging anything.",
+	Args:  cobra.NoArgs,
+	RunE:  runUnstage,
+}
+
+func init() {
+	unstageCmd.Flags().StringSlice("hunk", nil, "Unstage the hunk with this ID (repeatable)")
+	rootCmd.AddCommand(unstageCmd)
+}
+
+func runUnstage(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+	ids, _ := cmd.Flags().GetStringSlice("hunk")
+	if len(ids) == 0 {
+		return usageError(errors.New("name the hunks to unstage with --hunk"))
+	}
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	return runHunks(cmd, mode, repo, ids, false)
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/output"
+	worktreeui "github.com/madhermit/rift/internal/tui/worktree"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Worktree manager",
+	Long: `List, create, remove and switch between worktrees, including bare-repo
+layouts. A process can't change its parent shell's directory, so switching
+prints the worktree's path for the shell to cd into:
+
+  cd "$(rift worktree --print)"         # pick one
+  cd "$(rift worktree switch feature)"  # by branch or directory name
+
+--print is rift worktree switch; rift worktree list prints the worktrees.`,
+	Args: cobra.NoArgs,
+	RunE: runWorktree,
+}
+
+var worktreeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List worktrees",
+	Long:  "List every worktree: path, branch, HEAD and any of current, dirty, locked and prunable, tab-separated.",
+	Args:  cobra.NoArgs,
+	RunE:  runWorktreeList,
+}
+
+var worktreeAddCmd = &cobra.Command{
+	Use:   "add <branch> [path]",
+	Short: "Create a worktree for a branch",
+	Long: `Create a worktree checking out branch, or a new branch with -b. The path
+defaults to a sibling of the current worktree named after the branch.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runWorktreeAdd,
+}
+
+var worktreeRemoveCmd = &cobra.Command{
+	Use:   "remove <worktree>",
+	Short: "Remove a worktree",
+	Long:  "Remove a worktree by branch, path or directory name. Dirty or locked worktrees need --force.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWorktreeRemove,
+}
+
+var worktreePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Forget worktrees whose directories are gone",
+	Args:  cobra.NoArgs,
+	RunE:  runWorktreePrune,
+}
+
+var worktreeSwitchCmd = &cobra.Command{
+	Use:   "switch [worktree]",
+	Short: "Print a worktree's path to cd into",
+	Long: `Print the path of the worktree named by branch, path or directory name.
+Without a name, pick one from a list drawn on the terminal (stderr), so the
+path on stdout can be captured: cd "$(rift worktree switch)".`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWorktreeSwitch,
+}
+
+func init() {
+	worktreeAddCmd.Flags().BoolP("new-branch", "b", false, "Create the branch")
+	worktreeAddCmd.Flags().String("base", "", "Start point for the new branch (default HEAD)")
+	worktreeRemoveCmd.Flags().BoolP("force", "f", false, "Remove even if dirty or locked")
+
+	worktreeCmd.AddCommand(worktreeListCmd, worktreeAddCmd, worktreeRemoveCmd, worktreePruneCmd, worktreeSwitchCmd)
+	rootCmd.AddCommand(worktreeCmd)
+}
+
+func runWorktree(cmd *cobra.Command, args []string) error {
+	// Only an explicit --print switches: piped without it, rift worktree
+	// lists, as every command does.
+	if p, _ := cmd.Flags().GetBool("print"); p {
+		return runWorktreeSwitch(cmd, args)
+	}
+	mode := output.Detect(cmd)
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	if mode != output.Interactive {
+		return writeWorktrees(cmd, mode, worktrees)
+	}
+
+	m := worktreeui.New(repo, worktrees)
+	result, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return err
+	}
+
+	// Switching can only hand the path back; the shell does the cd.
+	if final, ok := result.(worktreeui.Model); ok && final.SwitchTo() != "" {
+		fmt.Println(final.SwitchTo())
+	}
+	return nil
+}
+
+func runWorktreeList(cmd *cobra.Command, args []string) error {
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	return writeWorktrees(cmd, output.Detect(cmd), worktrees)
+}
+
+func writeWorktrees(cmd *cobra.Command, mode output.Mode, worktrees []git.WorktreeInfo) error {
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, worktrees)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, worktrees)
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), worktrees)
+	}
+	lines := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		lines[i] = worktreeLine(wt)
+	}
+	return output.WritePlain(os.Stdout, lines)
+}
+
+// worktreeLine is how rift worktree list prints a worktree: path, branch (or what
+// stands in for it), HEAD and any flags, tab-separated.
+func worktreeLine(wt git.WorktreeInfo) string {
+	branch := wt.Branch
+	switch {
+	case wt.Bare:
+		branch = "(bare)"
+	case wt.Detached:
+		branch = "(detached)"
+	}
+	fields := []string{wt.Path, branch, wt.Head}
+	if wt.Current {
+		fields = append(fields, "current")
+	}
+	if wt.Dirty {
+		fields = append(fields, "dirty")
+	}
+	if wt.Locked {
+		fields = append(fields, "locked")
+	}
+	if wt.Prunable {
+		fields = append(fields, "prunable")
+	}
+	return strings.Join(fields, "\t")
+}
+
+func runWorktreeAdd(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+	newBranch, _ := cmd.Flags().GetBool("new-branch")
+	base, _ := cmd.Flags().GetString("base")
+	if base != "" && !newBranch {
+		return usageError(errors.New("--base only applies with -b"))
+	}
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	if base != "" {
+		if err := repo.VerifyRef(base); err != nil {
+			return err
+		}
+	}
+
+	branch := args[0]
+	path := repo.DefaultWorktreePath(branch)
+	if len(args) == 2 {
+		if path, err = filepath.Abs(args[1]); err != nil {
+			return err
+		}
+	}
+	if err := repo.AddWorktree(path, branch, newBranch, base); err != nil {
+		return err
+	}
+	return writeWorktree(mode, repo, path)
+}
+
+// writeWorktree reports the worktree at path after it was created: its
+// details as JSON, otherwise just the path.
+func writeWorktree(mode output.Mode, repo *git.Repo, path string) error {
+	if mode != output.JSON && mode != output.NDJSON {
+		fmt.Println(path)
+		return nil
+	}
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	wt, ok := git.FindWorktree(worktrees, path)
+	if !ok {
+		return fmt.Errorf("worktree %s not listed after creating it", path)
+	}
+	if mode == output.NDJSON {
+		return output.WriteNDJSON(os.Stdout, []git.WorktreeInfo{wt})
+	}
+	return output.WriteJSON(os.Stdout, wt)
+}
+
+func runWorktreeRemove(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+	force, _ := cmd.Flags().GetBool("force")
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	wt, err := findWorktree(repo, args[0])
+	if err != nil {
+		return err
+	}
+	if wt.Bare {
+		return fmt.Errorf("%s is the bare repository, not a worktree", wt.Path)
+	}
+	if err := repo.RemoveWorktree(wt.Path, force); err != nil {
+		return err
+	}
+
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, wt)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, []git.WorktreeInfo{wt})
+	}
+	fmt.Printf("Removed %s\n", wt.Path)
+	return nil
+}
+
+func runWorktreePrune(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	pruned, err := repo.PruneWorktrees()
+	if err != nil {
+		return err
+	}
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, pruned)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, pruned)
+	}
+	return output.WritePlain(os.Stdout, pruned)
+}
+
+func runWorktreeSwitch(cmd *cobra.Command, args []string) error {
+	mode := output.Detect(cmd)
+
+	repo, err := git.OpenRepo()
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	var wt git.WorktreeInfo
+	if len(args) == 1 {
+		if wt, err = findWorktree(repo, args[0]); err != nil {
+			return err
+		}
+	} else {
+		worktrees, err := repo.ListWorktrees()
+		if err != nil {
+			return err
+		}
+		// stdout is usually captured by $(...) here, so the picker draws on
+		// stderr and only the chosen path goes to stdout.
+		if mode == output.JSON || mode == output.NDJSON || !term.IsTerminal(int(os.Stderr.Fd())) {
+			return usageError(errors.New("name a worktree to switch to (no terminal to pick one on)"))
+		}
+		m := worktreeui.NewPicker(worktrees)
+		result, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr)).Run()
+		if err != nil {
+			return err
+		}
+		final, ok := result.(worktreeui.Model)
+		if !ok || final.SwitchTo() == "" {
+			return nil
+		}
+		wt, _ = git.FindWorktree(worktrees, final.SwitchTo())
+	}
+
+	if wt.Bare {
+		return fmt.Errorf("%s is the bare repository, not a worktree", wt.Path)
+	}
+	switch mode {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, wt)
+	case output.NDJSON:
+		return output.WriteNDJSON(os.Stdout, []git.WorktreeInfo{wt})
+	case output.Template:
+		return output.WriteTemplate(os.Stdout, output.TemplateFormat(cmd), []git.WorktreeInfo{wt})
+	}
+	fmt.Println(wt.Path)
+	return nil
+}
+
+func findWorktree(repo *git.Repo, name string) (git.WorktreeInfo, error) {
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return git.WorktreeInfo{}, err
+	}
+	wt, ok := git.FindWorktree(worktrees, name)
+	if !ok {
+		return git.WorktreeInfo{}, fmt.Errorf("no worktree matches %q", name)
+	}
+	return wt, nil
+}
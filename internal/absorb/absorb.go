@@ -0,0 +1,213 @@
+// Package absorb routes staged hunks to the unpushed commits that last
+// touched the lines they change, as fixup! commits.
+package absorb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+)
+
+// Assignment is the decision for one staged hunk: the commit it fixes up, or
+// the reason it stays staged.
+type Assignment struct {
+	Path    string `json:"path"`
+	Hunk    string `json:"hunk"`
+	Commit  string `json:"commit,omitempty"`
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+
+	file   *diff.FileDiff // the staged file the hunk belongs to
+	idx    int            // index of the hunk in file.Hunks
+	target string         // full hash of Commit
+}
+
+// Assigned reports whether the hunk has a target commit.
+func (a Assignment) Assigned() bool { return a.target != "" }
+
+// Plan blames every staged hunk against HEAD and assigns it to the commit
+// between base and HEAD that last changed all of its lines. Hunks in new
+// files, hunks whose lines come from several commits, and hunks whose lines
+// were last changed at or before base are left unassigned.
+func Plan(repo *git.Repo, base string) ([]Assignment, error) {
+	raw, err := repo.StagedPatch()
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := repo.RevList(base)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := repo.CommitsSince(base)
+	if err != nil {
+		return nil, err
+	}
+	unpushed := map[string]git.CommitInfo{}
+	for _, h := range hashes {
+		for _, c := range commits {
+			if strings.HasPrefix(h, c.Hash) {
+				unpushed[h] = c
+				break
+			}
+		}
+	}
+
+	plan := []Assignment{}
+	for _, fd := range diff.ParseUnifiedDiff(raw) {
+		var blame map[int]string
+		var blameErr error
+		newFile := strings.Contains(fd.Header, "\nnew file mode ")
+		if !newFile {
+			blame, blameErr = repo.Blame(fd.Path)
+		}
+
+		for i, h := range fd.Hunks {
+			a := Assignment{Path: fd.Path, Hunk: h.Header, file: &fd, idx: i}
+			switch {
+			case newFile:
+				a.Reason = "new file"
+			case blameErr != nil:
+				a.Reason = "no blame: " + blameErr.Error()
+			default:
+				assign(&a, touchedLines(h), blame, unpushed)
+			}
+			plan = append(plan, a)
+		}
+	}
+	return plan, nil
+}
+
+func assign(a *Assignment, lines []int, blame map[int]string, unpushed map[string]git.CommitInfo) {
+	owners := map[string]bool{}
+	for _, line := range lines {
+		if h, ok := blame[line]; ok {
+			owners[h] = true
+		}
+	}
+	if len(owners) == 0 {
+		a.Reason = "no blamed lines"
+		return
+	}
+	if len(owners) > 1 {
+		a.Reason = fmt.Sprintf("lines last changed by %d commits", len(owners))
+		return
+	}
+	for h := range owners {
+		c, ok := unpushed[h]
+		if !ok {
+			a.Reason = "lines last changed by a pushed commit"
+			return
+		}
+		a.target = h
+		a.Commit = c.Hash
+		a.Message = c.Message
+	}
+}
+
+// touchedLines returns the HEAD line numbers a zero-context hunk changes. A
+// pure insertion changes no existing line, so the lines either side of it
+// stand in for it.
+func touchedLines(h diff.Hunk) []int {
+	if h.OldCount == 0 {
+		// "-N,0" means the insertion goes after line N.
+		lines := []int{h.OldStart + 1}
+		if h.OldStart > 0 {
+			lines = append(lines, h.OldStart)
+		}
+		return lines
+	}
+	lines := make([]int, h.OldCount)
+	for i := range lines {
+		lines[i] = h.OldStart + i
+	}
+	return lines
+}
+
+// Apply creates one fixup! commit per target in plan, in the order targets
+// first appear, and leaves unassigned hunks staged. Each fixup is built in an
+// index of its own, so the repository's index is never rewritten: against the
+// new HEAD, it stages just the unassigned hunks. If anything fails, HEAD is
+// put back as it was.
+func Apply(repo *git.Repo, plan []Assignment) ([]git.CommitInfo, error) {
+	var order []string
+	groups := map[string][]Assignment{}
+	for _, a := range plan {
+		if !a.Assigned() {
+			continue
+		}
+		if _, ok := groups[a.target]; !ok {
+			order = append(order, a.target)
+		}
+		groups[a.target] = append(groups[a.target], a)
+	}
+	if len(order) == 0 {
+		return []git.CommitInfo{}, nil
+	}
+
+	origHead, err := repo.HeadHash()
+	if err != nil {
+		return nil, err
+	}
+	rollback := func(cause error) error {
+		if err := repo.ResetSoft(origHead); err != nil {
+			return fmt.Errorf("%w (restoring HEAD %s: %v)", cause, origHead[:7], err)
+		}
+		return cause
+	}
+
+	committed := map[*diff.FileDiff]map[int]bool{}
+	fixups := []git.CommitInfo{}
+	for _, target := range order {
+		info, err := repo.FixupPatch(target, groupPatch(groups[target], committed))
+		if err != nil {
+			return nil, rollback(err)
+		}
+		fixups = append(fixups, info)
+		for _, a := range groups[target] {
+			if committed[a.file] == nil {
+				committed[a.file] = map[int]bool{}
+			}
+			committed[a.file][a.idx] = true
+		}
+	}
+	return fixups, nil
+}
+
+// groupPatch builds a zero-context patch for one fixup. Hunk positions come
+// from the original HEAD, so each is moved past the line-count changes of
+// hunks earlier in its file that previous fixups committed (on the old side)
+// or that this patch applies first (on the new side).
+func groupPatch(hunks []Assignment, committed map[*diff.FileDiff]map[int]bool) string {
+	var b strings.Builder
+	var lastFile *diff.FileDiff
+	for _, a := range hunks {
+		header := ""
+		if a.file != lastFile {
+			header = a.file.Header
+			lastFile = a.file
+		}
+
+		var before, inPatch, all int
+		for i, h := range a.file.Hunks[:a.idx] {
+			delta := h.NewCount - h.OldCount
+			all += delta
+			if committed[a.file][i] {
+				before += delta
+			}
+		}
+		for _, other := range hunks {
+			if other.file == a.file && other.idx < a.idx {
+				inPatch += other.file.Hunks[other.idx].NewCount - other.file.Hunks[other.idx].OldCount
+			}
+		}
+
+		h := a.file.Hunks[a.idx]
+		// NewStart - OldStart - all is git's ±1 adjustment for empty ranges.
+		oldStart := h.OldStart + before
+		newStart := oldStart + (h.NewStart - h.OldStart - all) + inPatch
+		b.WriteString(h.Moved(oldStart, newStart).Patch(header))
+	}
+	return b.String()
+}
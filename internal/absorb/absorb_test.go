@@ -0,0 +1,123 @@
+package absorb
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+)
+
+func TestTouchedLines(t *testing.T) {
+	tests := []struct {
+		name string
+		hunk diff.Hunk
+		want []int
+	}{
+		{"replace", diff.Hunk{OldStart: 4, OldCount: 2, NewStart: 4, NewCount: 3}, []int{4, 5}},
+		{"delete", diff.Hunk{OldStart: 7, OldCount: 1, NewStart: 6, NewCount: 0}, []int{7}},
+		{"insert", diff.Hunk{OldStart: 3, OldCount: 0, NewStart: 4, NewCount: 2}, []int{4, 3}},
+		{"insert at top", diff.Hunk{OldStart: 0, OldCount: 0, NewStart: 1, NewCount: 1}, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := touchedLines(tt.hunk); !slices.Equal(got, tt.want) {
+				t.Errorf("touchedLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s: %v", strings.Join(args, " "), out, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	dir := t.TempDir()
+	for _, k := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(k+"_NAME", "Test")
+		t.Setenv(k+"_EMAIL", "test@test.com")
+	}
+	run(t, dir, "init", "-q")
+	write(t, dir, "f.txt", "p1\np2\np3\np4\np5\np6\np7\np8\n")
+	run(t, dir, "add", ".")
+	run(t, dir, "commit", "-qm", "base")
+	base := run(t, dir, "rev-parse", "HEAD")
+
+	write(t, dir, "f.txt", "p1\np2\np3\np4\np5\np6\np7\np8\na1\na2\n")
+	run(t, dir, "commit", "-qam", "Add a")
+	write(t, dir, "f.txt", "p1\nb1\np2\np3\np4\np5\np6\np7\np8\na1\na2\n")
+	run(t, dir, "commit", "-qam", "Add b")
+
+	// One change per commit, one to a pushed line, and a new file.
+	write(t, dir, "f.txt", "p1\nB1\np2\np3\nP4\np5\np6\np7\np8\nA1\na2\n")
+	write(t, dir, "new.txt", "new\n")
+	run(t, dir, "add", ".")
+	// An intent-to-add entry, which rewriting the index would drop.
+	write(t, dir, "later.txt", "later\n")
+	run(t, dir, "add", "-N", "later.txt")
+
+	t.Chdir(dir)
+	repo, err := git.OpenRepo()
+	if err != nil {
+		t.Fatalf("OpenRepo() error = %v", err)
+	}
+
+	plan, err := Plan(repo, base)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	var got []string
+	for _, a := range plan {
+		got = append(got, a.Path+" "+a.Message+a.Reason)
+	}
+	want := []string{
+		"f.txt Add b",
+		"f.txt lines last changed by a pushed commit",
+		"f.txt Add a",
+		"new.txt new file",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Plan() = %q, want %q", got, want)
+	}
+
+	fixups, err := Apply(repo, plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(fixups) != 2 || fixups[0].Message != "fixup! Add b" || fixups[1].Message != "fixup! Add a" {
+		t.Errorf("Apply() = %+v, want fixups for Add b then Add a", fixups)
+	}
+
+	staged := run(t, dir, "diff", "--cached", "-U0", "--no-color")
+	if !strings.Contains(staged, "+P4") || !strings.Contains(staged, "+new") {
+		t.Errorf("expected the unassigned hunks to stay staged, got:\n%s", staged)
+	}
+	if strings.Contains(staged, "B1") || strings.Contains(staged, "A1") {
+		t.Errorf("expected the assigned hunks to be committed, got:\n%s", staged)
+	}
+	if got := run(t, dir, "status", "--porcelain", "--", "later.txt"); got != "A later.txt" {
+		t.Errorf("status of later.txt = %q, want it still intent-to-add", got)
+	}
+	if got := run(t, dir, "show", "HEAD:f.txt"); got != "p1\nB1\np2\np3\np4\np5\np6\np7\np8\nA1\na2" {
+		t.Errorf("HEAD:f.txt = %q", got)
+	}
+}
@@ -0,0 +1,197 @@
+// Package cache keeps rendered diffs on disk between runs. Entries are
+// content-addressed: a key hashes everything the rendering depends on, so a
+// stale entry is never looked up, only evicted when the cache outgrows its
+// size limit.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxBytes is the size the cache is trimmed back to.
+const DefaultMaxBytes = 256 << 20
+
+// trimEvery is how many writes pass between trims; a trim walks the whole
+// cache, so it's too slow for every write.
+const trimEvery = 64
+
+// Cache is a directory of entries, least recently used first to go.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	writes   atomic.Int64
+}
+
+// Stats describes what the cache holds.
+type Stats struct {
+	Dir      string `json:"dir"`
+	Entries  int    `json:"entries"`
+	Bytes    int64  `json:"bytes"`
+	MaxBytes int64  `json:"max_bytes"`
+}
+
+// Dir is where rift caches: $XDG_CACHE_HOME/rift, or ~/.cache/rift.
+func Dir() (string, error) {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "rift"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "rift"), nil
+}
+
+// Open returns the cache in Dir, limited to DefaultMaxBytes.
+func Open() (*Cache, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return New(dir, DefaultMaxBytes), nil
+}
+
+// New returns a cache in dir, trimmed back to maxBytes as it grows. The
+// directory is created on the first write.
+func New(dir string, maxBytes int64) *Cache {
+	return &Cache{dir: dir, maxBytes: maxBytes}
+}
+
+// Key hashes parts into a key. Parts are kept apart, so ("ab", "c") and
+// ("a", "bc") are different keys.
+func Key(parts ...string) string {
+	sum := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(sum, "%d:%s\x00", len(p), p)
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, "diffs", key[:2], key[2:])
+}
+
+// Get returns the entry for key, if there is one, and marks it recently
+// used.
+func (c *Cache) Get(key string) (string, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return string(data), true
+}
+
+// Put stores value under key. A cache that can't be written to is no
+// worse than no cache, so failures are ignored.
+func (c *Cache) Put(key, value string) {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	// Write then rename, so concurrent readers never see half an entry.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.WriteString(value)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if c.writes.Add(1)%trimEvery == 1 {
+		c.Trim()
+	}
+}
+
+type entry struct {
+	path string
+	size int64
+	used time.Time
+}
+
+func (c *Cache) entries() ([]entry, error) {
+	var entries []entry
+	err := filepath.WalkDir(filepath.Join(c.dir, "diffs"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed by another process mid-walk
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), used: info.ModTime()})
+		return nil
+	})
+	return entries, err
+}
+
+// Trim evicts the least recently used entries until the cache fits its
+// size limit.
+func (c *Cache) Trim() error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+	if total <= c.maxBytes {
+		return nil
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return a.used.Compare(b.used) })
+	for _, e := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err == nil || errors.Is(err, fs.ErrNotExist) {
+			total -= e.size
+		}
+	}
+	return nil
+}
+
+// Stats counts the cache's entries and their size.
+func (c *Cache) Stats() (Stats, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return Stats{}, err
+	}
+	s := Stats{Dir: c.dir, Entries: len(entries), MaxBytes: c.maxBytes}
+	for _, e := range entries {
+		s.Bytes += e.size
+	}
+	return s, nil
+}
+
+// Clear removes every entry and returns what it removed.
+func (c *Cache) Clear() (Stats, error) {
+	s, err := c.Stats()
+	if err != nil {
+		return Stats{}, err
+	}
+	if err := os.RemoveAll(filepath.Join(c.dir, "diffs")); err != nil {
+		return Stats{}, fmt.Errorf("clear cache: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_GetPut(t *testing.T) {
+	c := New(t.TempDir(), 1<<20)
+	key := Key("a", "b")
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get() on an empty cache hit")
+	}
+	c.Put(key, "rendered")
+	if got, ok := c.Get(key); !ok || got != "rendered" {
+		t.Errorf("Get() = %q, %v, want rendered", got, ok)
+	}
+	if Key("ab", "") == Key("a", "b") {
+		t.Error("Key() runs its parts together")
+	}
+}
+
+func TestCache_Trim(t *testing.T) {
+	c := New(t.TempDir(), 250)
+	keys := []string{Key("old"), Key("used"), Key("new")}
+	for i, key := range keys {
+		c.Put(key, strings.Repeat("x", 100))
+		// Give each entry its own time; filesystems may store coarse ones.
+		at := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(c.path(key), at, at)
+	}
+	c.Get(keys[1])
+
+	if err := c.Trim(); err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	if _, ok := c.Get(keys[0]); ok {
+		t.Error("Trim() kept the least recently used entry")
+	}
+	for _, key := range keys[1:] {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Trim() evicted %s, which was used more recently", key[:8])
+		}
+	}
+}
+
+func TestCache_StatsClear(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, 1<<20)
+	c.Put(Key("a"), "12345")
+	c.Put(Key("b"), "123")
+	// Leftovers of an interrupted write aren't entries.
+	os.WriteFile(filepath.Join(filepath.Dir(c.path(Key("a"))), ".tmp-1"), []byte("x"), 0600)
+
+	s, err := c.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if s.Entries != 2 || s.Bytes != 8 || s.Dir != dir {
+		t.Errorf("Stats() = %+v, want 2 entries of 8 bytes in %s", s, dir)
+	}
+
+	cleared, err := c.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if cleared.Entries != 2 {
+		t.Errorf("Clear() = %+v, want the 2 entries removed", cleared)
+	}
+	if s, _ := c.Stats(); s.Entries != 0 {
+		t.Errorf("Stats() after Clear() = %+v, want empty", s)
+	}
+}
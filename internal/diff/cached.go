@@ -0,0 +1,152 @@
+package diff
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/madhermit/rift/internal/cache"
+)
+
+// cachedEngine keeps an engine's renders in the on-disk cache. Keys are
+// built from the blobs or trees on each side rather than from refs, so an
+// entry stays valid however the same content is reached again, and goes
+// unused as soon as the content changes.
+type cachedEngine struct {
+	Engine
+	cache   *cache.Cache
+	version func() string
+}
+
+func newCachedEngine(e Engine, c *cache.Cache, version func() string) *cachedEngine {
+	return &cachedEngine{Engine: e, cache: c, version: sync.OnceValue(version)}
+}
+
+func (c *cachedEngine) key(kind string, width int, color bool, parts ...string) string {
+	return cache.Key(append([]string{kind, c.Name(), c.version(), strconv.Itoa(width), strconv.FormatBool(color)}, parts...)...)
+}
+
+func (c *cachedEngine) Diff(ctx context.Context, repoRoot, file string, opts DiffOpts) (string, error) {
+	oldID, newID, ok := sideIDs(ctx, repoRoot, file, opts)
+	if !ok {
+		return c.Engine.Diff(ctx, repoRoot, file, opts)
+	}
+	key := c.key("file", opts.Width, opts.Color, file, opts.OldPath, oldID, newID)
+	if out, ok := c.cache.Get(key); ok {
+		return out, nil
+	}
+	out, err := c.Engine.Diff(ctx, repoRoot, file, opts)
+	if err == nil && ctx.Err() == nil {
+		c.cache.Put(key, out)
+	}
+	return out, err
+}
+
+func (c *cachedEngine) DiffCommit(ctx context.Context, repoRoot, base, target string, color bool, width int) (string, error) {
+	ids, err := objects(repoRoot).ObjectIDs(ctx, base+"^{tree}", target+"^{tree}")
+	if err != nil || ids[0] == "" || ids[1] == "" {
+		return c.Engine.DiffCommit(ctx, repoRoot, base, target, color, width)
+	}
+	key := c.key("commit", width, color, ids[0], ids[1])
+	if out, ok := c.cache.Get(key); ok {
+		return out, nil
+	}
+	out, err := c.Engine.DiffCommit(ctx, repoRoot, base, target, color, width)
+	if err == nil && ctx.Err() == nil {
+		c.cache.Put(key, out)
+	}
+	return out, err
+}
+
+// DiffHunks renders only the hunks the cache doesn't have. A hunk that
+// fell back to its raw lines isn't cached, so it gets another try.
+func (c *cachedEngine) DiffHunks(ctx context.Context, hunks []Hunk, filename, baseContent string, color bool, width int) []string {
+	base := sha256.Sum256([]byte(baseContent))
+	results := make([]string, len(hunks))
+	keys := make([]string, len(hunks))
+	var missing []int
+	for i, h := range hunks {
+		keys[i] = c.key("hunk", width, color, filepath.Ext(filename), hex.EncodeToString(base[:]), h.Header, strings.Join(h.Lines, "\n"))
+		if out, ok := c.cache.Get(keys[i]); ok {
+			results[i] = out
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return results
+	}
+
+	todo := make([]Hunk, len(missing))
+	for j, i := range missing {
+		todo[j] = hunks[i]
+	}
+	rendered := c.Engine.DiffHunks(ctx, todo, filename, baseContent, color, width)
+	for j, i := range missing {
+		results[i] = rendered[j]
+		h := hunks[i]
+		if ctx.Err() == nil && rendered[j] != h.Header+"\n"+strings.Join(h.Lines, "\n") {
+			c.cache.Put(keys[i], rendered[j])
+		}
+	}
+	return results
+}
+
+// sideIDs identifies the content on each side of a file diff, the sides
+// chosen as sidePaths and buildGitDiffArgs choose them: blob IDs for
+// commits and the index, a hash of the contents and mode for a worktree
+// file, and "" for a side that doesn't exist. ok is false if git can't
+// say.
+func sideIDs(ctx context.Context, repoRoot, file string, opts DiffOpts) (oldID, newID string, ok bool) {
+	oldFile := file
+	if opts.OldPath != "" {
+		oldFile = opts.OldPath
+	}
+	var oldSpec, newSpec string
+	switch {
+	case opts.Untracked:
+		return "", worktreeID(repoRoot, file), true
+	case opts.Base != "" && opts.Target != "":
+		oldSpec, newSpec = opts.Base+":"+oldFile, opts.Target+":"+file
+	case opts.Staged:
+		oldSpec, newSpec = "HEAD:"+oldFile, ":"+file
+	case opts.Base != "":
+		oldSpec = opts.Base + ":" + oldFile
+	default:
+		oldSpec = ":" + oldFile
+	}
+
+	specs := []string{oldSpec}
+	if newSpec != "" {
+		specs = append(specs, newSpec)
+	}
+	ids, err := objects(repoRoot).ObjectIDs(ctx, specs...)
+	if err != nil {
+		return "", "", false
+	}
+	if newSpec == "" {
+		return ids[0], worktreeID(repoRoot, file), true
+	}
+	return ids[0], ids[1], true
+}
+
+// worktreeID hashes a worktree file's contents and mode, or returns "" if
+// it doesn't exist.
+func worktreeID(repoRoot, file string) string {
+	path := filepath.Join(repoRoot, file)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "worktree:" + info.Mode().String() + ":" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/madhermit/rift/internal/cache"
+)
+
+// countingEngine renders a diff as a call count, so a repeated render
+// shows up as a new result.
+type countingEngine struct {
+	fallbackEngine
+	calls int
+}
+
+func (e *countingEngine) Diff(context.Context, string, string, DiffOpts) (string, error) {
+	e.calls++
+	return "render " + strconv.Itoa(e.calls), nil
+}
+
+func (e *countingEngine) DiffCommit(context.Context, string, string, string, bool, int) (string, error) {
+	e.calls++
+	return "commit " + strconv.Itoa(e.calls), nil
+}
+
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "root"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	return dir
+}
+
+func TestCachedEngine_Diff(t *testing.T) {
+	repo := gitRepo(t)
+	inner := &countingEngine{}
+	e := newCachedEngine(inner, cache.New(t.TempDir(), 1<<20), func() string { return "1" })
+	ctx := context.Background()
+	os.WriteFile(filepath.Join(repo, "f.txt"), []byte("one\n"), 0644)
+	opts := DiffOpts{Untracked: true, Width: 80}
+
+	first, _ := e.Diff(ctx, repo, "f.txt", opts)
+	again, _ := e.Diff(ctx, repo, "f.txt", opts)
+	if first != again || inner.calls != 1 {
+		t.Errorf("second render = %q after %d calls, want %q from the cache", again, inner.calls, first)
+	}
+
+	opts.Width = 100
+	if e.Diff(ctx, repo, "f.txt", opts); inner.calls != 2 {
+		t.Error("a different width was served from the cache")
+	}
+
+	os.WriteFile(filepath.Join(repo, "f.txt"), []byte("two\n"), 0644)
+	if changed, _ := e.Diff(ctx, repo, "f.txt", opts); changed == first || inner.calls != 3 {
+		t.Error("changed contents were served from the cache")
+	}
+
+	if c, _ := e.DiffCommit(ctx, repo, "HEAD", "HEAD", false, 80); c != "commit 4" {
+		t.Errorf("DiffCommit() = %q, want a fresh render", c)
+	}
+	if c, _ := e.DiffCommit(ctx, repo, "HEAD~0", "HEAD", false, 80); c != "commit 4" {
+		t.Errorf("DiffCommit() of the same trees = %q, want the cached render", c)
+	}
+}
+
+func TestCachedEngine_DiffHunks(t *testing.T) {
+	inner := &fallbackEngine{}
+	c := cache.New(t.TempDir(), 1<<20)
+	e := newCachedEngine(inner, c, func() string { return "1" })
+	hunks := []Hunk{{Header: "@@ -1 +1 @@", Lines: []string{"-a", "+b"}}}
+
+	// The fallback engine's plain output is the raw-lines fallback, which
+	// must not be cached.
+	e.DiffHunks(context.Background(), hunks, "f.go", "a\n", false, 80)
+	if s, _ := c.Stats(); s.Entries != 0 {
+		t.Errorf("raw-lines fallback was cached: %+v", s)
+	}
+	got := e.DiffHunks(context.Background(), hunks, "f.go", "a\n", true, 80)
+	if s, _ := c.Stats(); s.Entries != 1 {
+		t.Errorf("rendered hunk wasn't cached: %+v", s)
+	}
+	if again := e.DiffHunks(context.Background(), hunks, "f.go", "a\n", true, 80); again[0] != got[0] {
+		t.Errorf("cached hunk = %q, want %q", again[0], got[0])
+	}
+}
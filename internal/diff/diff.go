@@ -5,30 +5,49 @@ import (
 	"fmt"
 	"os/exec"
 
+	"github.com/madhermit/rift/internal/cache"
+	"github.com/madhermit/rift/internal/git"
 	"github.com/madhermit/rift/internal/tooling"
 )
 
 type DiffOpts struct {
-	Staged bool
-	Base   string
-	Target string
-	Color  bool
-	Width  int
+	OldPath   string // previous path when the file was renamed or copied
+	Untracked bool   // the file is new to git: diff it against /dev/null
+	Staged    bool
+	Base      string
+	Target    string
+	Color     bool
+	Width     int
 }
 
 type Engine interface {
 	Diff(ctx context.Context, repoRoot, file string, opts DiffOpts) (string, error)
 	DiffCommit(ctx context.Context, repoRoot, base, target string, color bool, width int) (string, error)
 	DiffHunks(ctx context.Context, hunks []Hunk, filename, baseContent string, color bool, width int) []string
+	DiffStructured(ctx context.Context, repoRoot, file string, opts DiffOpts) (*StructuredDiff, error)
 	Name() string
 }
 
+// NewEngine returns difftastic if it can be found or installed, with its
+// renders cached on disk, or plain git diff, which is quick enough not to
+// need caching.
 func NewEngine() Engine {
 	path, err := tooling.FindOrInstallDifft()
-	if err == nil && path != "" {
-		return &difftasticEngine{path: path}
+	if err != nil || path == "" {
+		return &fallbackEngine{}
 	}
-	return &fallbackEngine{}
+	engine := &difftasticEngine{path: path}
+	c, err := cache.Open()
+	if err != nil {
+		return engine
+	}
+	return newCachedEngine(engine, c, engine.version)
+}
+
+// objects returns the repository's shared git.ObjectReader, so every blob
+// the engines read for it comes from the same git process.
+func objects(repoRoot string) *git.ObjectReader {
+	return git.Objects(repoRoot)
 }
 
 func buildCommitDiffArgs(base, target string, color bool) []string {
@@ -38,7 +57,7 @@ func buildCommitDiffArgs(base, target string, color bool) []string {
 	} else {
 		args = append(args, "--color=never")
 	}
-	args = append(args, base+".."+target)
+	args = append(args, base, target)
 	return args
 }
 
@@ -49,6 +68,9 @@ func buildGitDiffArgs(opts DiffOpts, file string) []string {
 	} else {
 		args = append(args, "--color=never")
 	}
+	if opts.Untracked && file != "" {
+		return append(args, "--no-index", "--", "/dev/null", file)
+	}
 	if opts.Staged {
 		args = append(args, "--staged")
 	} else if opts.Base != "" && opts.Target != "" {
@@ -56,7 +78,9 @@ func buildGitDiffArgs(opts DiffOpts, file string) []string {
 	} else if opts.Base != "" {
 		args = append(args, opts.Base)
 	}
-	if file != "" {
+	if opts.OldPath != "" && file != "" {
+		args = append(args, "-M", "-C", "--", opts.OldPath, file)
+	} else if file != "" {
 		args = append(args, "--", file)
 	}
 	return args
@@ -24,6 +24,12 @@ func TestBuildGitDiffArgs(t *testing.T) {
 			file: "main.go",
 			want: []string{"diff", "--color=never", "--staged", "--", "main.go"},
 		},
+		{
+			name: "untracked against /dev/null",
+			opts: DiffOpts{Untracked: true, Base: "HEAD"},
+			file: "new.go",
+			want: []string{"diff", "--color=never", "--no-index", "--", "/dev/null", "new.go"},
+		},
 		{
 			name: "base only",
 			opts: DiffOpts{Base: "HEAD~1"},
@@ -48,6 +54,12 @@ func TestBuildGitDiffArgs(t *testing.T) {
 			file: "f.go",
 			want: []string{"diff", "--color=always", "a", "b", "--", "f.go"},
 		},
+		{
+			name: "rename passes both paths",
+			opts: DiffOpts{OldPath: "old.go", Staged: true},
+			file: "new.go",
+			want: []string{"diff", "--color=never", "--staged", "-M", "-C", "--", "old.go", "new.go"},
+		},
 		{
 			name: "empty file omits separator",
 			opts: DiffOpts{Color: true},
@@ -85,14 +97,14 @@ func TestBuildCommitDiffArgs(t *testing.T) {
 			base:   "abc",
 			target: "def",
 			color:  true,
-			want:   []string{"diff", "--color=always", "abc..def"},
+			want:   []string{"diff", "--color=always", "abc", "def"},
 		},
 		{
 			name:   "no color",
 			base:   "abc",
 			target: "def",
 			color:  false,
-			want:   []string{"diff", "--color=never", "abc..def"},
+			want:   []string{"diff", "--color=never", "abc", "def"},
 		},
 	}
 
@@ -106,6 +118,19 @@ func TestBuildCommitDiffArgs(t *testing.T) {
 	}
 }
 
+func TestParseNameStatusZ(t *testing.T) {
+	out := "M\x00main.go\x00R086\x00old.go\x00new.go\x00D\x00gone.go\x00"
+	got := parseNameStatusZ(out)
+	want := []filePair{
+		{old: "main.go", new: "main.go"},
+		{old: "old.go", new: "new.go"},
+		{old: "gone.go", new: "gone.go"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseNameStatusZ() = %v, want %v", got, want)
+	}
+}
+
 func TestNewEngine(t *testing.T) {
 	engine := NewEngine()
 	name := engine.Name()
@@ -17,6 +17,16 @@ type difftasticEngine struct {
 
 func (d *difftasticEngine) Name() string { return "difftastic" }
 
+// version is difft's version line, which cached renders are keyed on: a
+// new difft may render the same diff differently.
+func (d *difftasticEngine) version() string {
+	out, err := exec.Command(d.path, "--version").Output()
+	if err != nil {
+		return d.path
+	}
+	return strings.TrimSpace(string(out))
+}
+
 func (d *difftasticEngine) Diff(ctx context.Context, repoRoot, file string, opts DiffOpts) (string, error) {
 	if opts.Width <= 0 {
 		return d.diffViaGit(ctx, repoRoot, file, opts)
@@ -43,11 +53,20 @@ func (d *difftasticEngine) diffDirect(ctx context.Context, repoRoot, file string
 	}
 	defer os.RemoveAll(tmpDir)
 
+	oldPath, newPath := d.sidePaths(ctx, repoRoot, file, opts, tmpDir)
+	return d.diffFiles(ctx, oldPath, newPath, opts.Color, opts.Width)
+}
+
+// sidePaths extracts the old and new versions of file into tmpDir as needed
+// and returns the paths to hand to difft.
+func (d *difftasticEngine) sidePaths(ctx context.Context, repoRoot, file string, opts DiffOpts, tmpDir string) (string, string) {
 	// Old side is always extracted from a git ref; new side is either
 	// extracted (base+target, staged) or the working tree file.
 	var oldRef string
 	var newPath string
 	switch {
+	case opts.Untracked:
+		return "/dev/null", filepath.Join(repoRoot, file)
 	case opts.Base != "" && opts.Target != "":
 		oldRef = opts.Base
 		newPath = showOrNull(ctx, repoRoot, opts.Target, file, filepath.Join(tmpDir, "b", file))
@@ -61,21 +80,56 @@ func (d *difftasticEngine) diffDirect(ctx context.Context, repoRoot, file string
 		newPath = filepath.Join(repoRoot, file)
 	}
 
-	oldPath := showOrNull(ctx, repoRoot, oldRef, file, filepath.Join(tmpDir, "a", file))
-	return d.diffFiles(ctx, oldPath, newPath, opts.Color, opts.Width)
+	oldFile := file
+	if opts.OldPath != "" {
+		oldFile = opts.OldPath
+	}
+	oldPath := showOrNull(ctx, repoRoot, oldRef, oldFile, filepath.Join(tmpDir, "a", oldFile))
+	return oldPath, newPath
+}
+
+// DiffStructured runs difft in its JSON display mode and parses the result.
+// The JSON display is still marked unstable upstream, hence DFT_UNSTABLE.
+func (d *difftasticEngine) DiffStructured(ctx context.Context, repoRoot, file string, opts DiffOpts) (*StructuredDiff, error) {
+	tmpDir, err := os.MkdirTemp("", "rift-diff-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	oldPath, newPath := d.sidePaths(ctx, repoRoot, file, opts, tmpDir)
+
+	cmd := exec.CommandContext(ctx, d.path, "--display", "json", oldPath, newPath)
+	cmd.Env = append(cmd.Environ(), "DFT_UNSTABLE=yes")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// difft exits 1 when there are differences — that's not an error
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("difft --display json %s: %w: %s", file, err, stderr.String())
+		}
+	}
+
+	sd, err := parseDifftJSON(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	sd.Path = file
+	return sd, nil
 }
 
 func (d *difftasticEngine) DiffCommit(ctx context.Context, repoRoot, base, target string, color bool, width int) (string, error) {
-	// Get list of changed files
-	cmd := exec.CommandContext(ctx, "git", "diff", "--name-only", base+".."+target)
+	// Get list of changed files, pairing renames and copies with their source
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-status", "-z", "-M", "-C", base, target)
 	cmd.Dir = repoRoot
 	out, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("git diff --name-only: %w", err)
+		return "", fmt.Errorf("git diff --name-status: %w", err)
 	}
 
-	files := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(files) == 0 || (len(files) == 1 && files[0] == "") {
+	files := parseNameStatusZ(string(out))
+	if len(files) == 0 {
 		return "", nil
 	}
 
@@ -89,15 +143,34 @@ func (d *difftasticEngine) DiffCommit(ctx context.Context, repoRoot, base, targe
 	aDir := filepath.Join(tmpDir, "a")
 	bDir := filepath.Join(tmpDir, "b")
 
-	var result strings.Builder
-	for _, file := range files {
-		oldPath := showOrNull(ctx, repoRoot, base, file, filepath.Join(aDir, file))
-		newPath := showOrNull(ctx, repoRoot, target, file, filepath.Join(bDir, file))
+	// Copies can share a source; files render concurrently, so each copy
+	// after the first extracts its source to a directory of its own.
+	oldDirs := make([]string, len(files))
+	seen := map[string]bool{}
+	for i, f := range files {
+		oldDirs[i] = aDir
+		if seen[f.old] {
+			oldDirs[i] = filepath.Join(tmpDir, strconv.Itoa(i), "a")
+		}
+		seen[f.old] = true
+	}
 
+	outs := RenderAll(ctx, len(files), func(ctx context.Context, i int) string {
+		f := files[i]
+		oldPath := showOrNull(ctx, repoRoot, base, f.old, filepath.Join(oldDirs[i], f.old))
+		newPath := showOrNull(ctx, repoRoot, target, f.new, filepath.Join(bDir, f.new))
 		diffOut, err := d.diffFiles(ctx, oldPath, newPath, color, width)
 		if err != nil {
-			continue
+			return ""
 		}
+		return diffOut
+	})
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	for _, diffOut := range outs {
 		if diffOut != "" {
 			result.WriteString(diffOut)
 			result.WriteString("\n")
@@ -106,6 +179,29 @@ func (d *difftasticEngine) DiffCommit(ctx context.Context, repoRoot, base, targe
 	return result.String(), nil
 }
 
+// filePair names a file on each side of a commit diff. The paths differ only
+// for renames and copies.
+type filePair struct {
+	old, new string
+}
+
+// parseNameStatusZ parses `git diff --name-status -z` output, where renames
+// and copies ("R086", "C100") are followed by both the old and new path.
+func parseNameStatusZ(out string) []filePair {
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	var pairs []filePair
+	for i := 0; i+1 < len(fields); i += 2 {
+		code, path := fields[i], fields[i+1]
+		if (strings.HasPrefix(code, "R") || strings.HasPrefix(code, "C")) && i+2 < len(fields) {
+			pairs = append(pairs, filePair{old: path, new: fields[i+2]})
+			i++
+			continue
+		}
+		pairs = append(pairs, filePair{old: path, new: path})
+	}
+	return pairs
+}
+
 // diffFiles calls difft directly in 2-arg mode. Note: difft ignores --width
 // for pure additions (old=/dev/null) even in side-by-side mode. Callers should
 // hard-wrap the output as a safety net. See https://github.com/Wilfred/difftastic/issues/861
@@ -141,17 +237,15 @@ func (d *difftasticEngine) diffFiles(ctx context.Context, oldPath, newPath strin
 // accurate syntax-aware diffs. Falls back to raw lines if difft fails.
 func (d *difftasticEngine) DiffHunks(ctx context.Context, hunks []Hunk, filename, baseContent string, color bool, width int) []string {
 	ext := filepath.Ext(filename)
-	results := make([]string, len(hunks))
-	for i, h := range hunks {
+	return RenderAll(ctx, len(hunks), func(ctx context.Context, i int) string {
+		h := hunks[i]
 		newContent := ApplyHunk(baseContent, h)
 		rendered, err := d.diffContent(ctx, baseContent, newContent, ext, color, width)
 		if err != nil || strings.TrimSpace(rendered) == "" {
-			results[i] = h.Header + "\n" + strings.Join(h.Lines, "\n")
-		} else {
-			results[i] = rendered
+			return h.Header + "\n" + strings.Join(h.Lines, "\n")
 		}
-	}
-	return results
+		return rendered
+	})
 }
 
 func (d *difftasticEngine) diffContent(ctx context.Context, old, new, ext string, color bool, width int) (string, error) {
@@ -173,16 +267,16 @@ func (d *difftasticEngine) diffContent(ctx context.Context, old, new, ext string
 }
 
 func showOrNull(ctx context.Context, repoRoot, ref, file, destPath string) string {
-	if err := gitShow(ctx, repoRoot, ref, file, destPath); err != nil {
+	if err := writeBlob(ctx, repoRoot, ref, file, destPath); err != nil {
 		return "/dev/null"
 	}
 	return destPath
 }
 
-func gitShow(ctx context.Context, repoRoot, ref, file, destPath string) error {
-	cmd := exec.CommandContext(ctx, "git", "show", ref+":"+file)
-	cmd.Dir = repoRoot
-	out, err := cmd.Output()
+// writeBlob writes file as it is at ref, or in the index if ref is "", to
+// destPath.
+func writeBlob(ctx context.Context, repoRoot, ref, file, destPath string) error {
+	out, err := objects(repoRoot).Read(ctx, ref+":"+file)
 	if err != nil {
 		return err
 	}
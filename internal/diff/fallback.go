@@ -25,6 +25,20 @@ func (f *fallbackEngine) DiffCommit(ctx context.Context, repoRoot, base, target
 	return runGitDiff(cmd, "git diff commit")
 }
 
+// DiffStructured reports line-level changes parsed from git diff, since
+// token-level spans need difftastic.
+func (f *fallbackEngine) DiffStructured(ctx context.Context, repoRoot, file string, opts DiffOpts) (*StructuredDiff, error) {
+	opts.Color = false
+	args := buildGitDiffArgs(opts, file)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoRoot
+	raw, err := runGitDiff(cmd, "git diff")
+	if err != nil {
+		return nil, err
+	}
+	return structuredFromUnified(raw, file), nil
+}
+
 func (f *fallbackEngine) DiffHunks(_ context.Context, hunks []Hunk, _, _ string, color bool, _ int) []string {
 	results := make([]string, len(hunks))
 	for i, h := range hunks {
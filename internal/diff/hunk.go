@@ -1,6 +1,8 @@
 package diff
 
 import (
+	"context"
+	"fmt"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -181,15 +183,14 @@ func ApplyHunk(base string, h Hunk) string {
 }
 
 // BaseContent retrieves the base file content for diffing.
-// For unstaged diffs: the index version (git show :file).
-// For staged diffs: the HEAD version (git show HEAD:file).
+// For unstaged diffs: the index version (:file).
+// For staged diffs: the HEAD version (HEAD:file).
 func BaseContent(repoRoot string, staged bool, file string) (string, error) {
 	ref := ":" + file // index version
 	if staged {
 		ref = "HEAD:" + file
 	}
-	cmd := exec.Command("git", "-C", repoRoot, "show", ref)
-	out, err := cmd.Output()
+	out, err := objects(repoRoot).Read(context.Background(), ref)
 	if err != nil {
 		return "", err
 	}
@@ -208,6 +209,168 @@ func (h Hunk) Patch(fileHeader string) string {
 	return b.String()
 }
 
+// PartialHunk returns a copy of h that keeps only the selected change lines
+// (indices into h.Lines). The result applies forward against the hunk's old
+// side: unselected removals become context and unselected additions are
+// dropped. With reverse set the roles swap, producing a patch to reverse-apply
+// against the new side, as when unstaging part of a staged hunk. It reports
+// false when no change line is selected.
+func (h Hunk) PartialHunk(selected map[int]bool, reverse bool) (Hunk, bool) {
+	keep, drop := byte('-'), byte('+')
+	if reverse {
+		keep, drop = '+', '-'
+	}
+
+	p := Hunk{OldStart: h.OldStart, NewStart: h.NewStart}
+	changed := false
+	lastKept := false
+	for i, line := range h.Lines {
+		if len(line) == 0 {
+			continue
+		}
+		switch line[0] {
+		case '\\':
+			// "\ No newline at end of file" annotates the preceding line.
+			if lastKept {
+				p.Lines = append(p.Lines, line)
+			}
+			continue
+		case '+', '-':
+			if selected[i] {
+				changed = true
+				p.Lines = append(p.Lines, line)
+			} else if line[0] == keep {
+				p.Lines = append(p.Lines, " "+line[1:])
+			} else if line[0] == drop {
+				lastKept = false
+				continue
+			}
+		default:
+			p.Lines = append(p.Lines, line)
+		}
+		lastKept = true
+	}
+	if !changed {
+		return Hunk{}, false
+	}
+
+	for _, line := range p.Lines {
+		switch line[0] {
+		case ' ':
+			p.OldCount++
+			p.NewCount++
+		case '-':
+			p.OldCount++
+		case '+':
+			p.NewCount++
+		}
+	}
+	p.Header = formatHunkHeader(p, hunkSection(h.Header))
+	return p, true
+}
+
+// SplitHunk breaks h into the smallest independent hunks, one per run of
+// changes separated by context, as git add -p does. Context between two runs
+// is shared by both sides. Start lines are recomputed so each sub-hunk can be
+// applied on its own; a hunk with a single run is returned unchanged.
+func SplitHunk(h Hunk) []Hunk {
+	type run struct{ start, end int } // change lines h.Lines[start:end]
+	var runs []run
+	for i := 0; i < len(h.Lines); i++ {
+		if !isChangeLine(h.Lines[i]) {
+			continue
+		}
+		r := run{start: i}
+		for i < len(h.Lines) && (isChangeLine(h.Lines[i]) || strings.HasPrefix(h.Lines[i], "\\")) {
+			i++
+		}
+		r.end = i
+		runs = append(runs, r)
+	}
+	if len(runs) < 2 {
+		return []Hunk{h}
+	}
+
+	section := hunkSection(h.Header)
+	result := make([]Hunk, 0, len(runs))
+	oldLine, newLine := h.OldStart, h.NewStart
+	delta := newLine - oldLine
+	pos := 0
+	for i, r := range runs {
+		// Each sub-hunk spans from the end of the previous run (or the hunk
+		// start) to the start of the next run (or the hunk end).
+		from := pos
+		to := len(h.Lines)
+		if i+1 < len(runs) {
+			to = runs[i+1].start
+		}
+
+		sub := Hunk{
+			OldStart: oldLine,
+			NewStart: oldLine + delta,
+			Lines:    append([]string(nil), h.Lines[from:to]...),
+		}
+		for _, line := range sub.Lines {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				sub.OldCount++
+			case strings.HasPrefix(line, "+"):
+				sub.NewCount++
+			case strings.HasPrefix(line, "\\"):
+			default:
+				sub.OldCount++
+				sub.NewCount++
+			}
+		}
+		sub.Header = formatHunkHeader(sub, section)
+		result = append(result, sub)
+
+		// The next sub-hunk starts at the context just after this run.
+		for _, line := range h.Lines[from:r.end] {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldLine++
+			case strings.HasPrefix(line, "+"):
+				newLine++
+			case strings.HasPrefix(line, "\\"):
+			default:
+				oldLine++
+				newLine++
+			}
+		}
+		delta = newLine - oldLine
+		pos = r.end
+	}
+	return result
+}
+
+// Moved returns a copy of h starting at the given old and new lines, with
+// its header rewritten to match. It is used to apply a hunk against a file
+// that other hunks have already shifted.
+func (h Hunk) Moved(oldStart, newStart int) Hunk {
+	h.OldStart, h.NewStart = oldStart, newStart
+	h.Header = formatHunkHeader(h, hunkSection(h.Header))
+	return h
+}
+
+func isChangeLine(line string) bool {
+	return strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")
+}
+
+// hunkSection returns the function context git appends after the closing
+// "@@" of a hunk header, including its leading space.
+func hunkSection(header string) string {
+	rest := strings.TrimPrefix(header, "@@ ")
+	if i := strings.Index(rest, " @@"); i >= 0 {
+		return rest[i+3:]
+	}
+	return ""
+}
+
+func formatHunkHeader(h Hunk, section string) string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", h.OldStart, h.OldCount, h.NewStart, h.NewCount, section)
+}
+
 func RawUnifiedDiff(repoRoot string, staged bool, file string) (string, error) {
 	args := []string{"diff", "--no-color"}
 	if staged {
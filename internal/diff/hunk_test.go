@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPartialHunk(t *testing.T) {
+	h := Hunk{
+		Header:   "@@ -1,4 +1,4 @@ func main() {",
+		OldStart: 1, OldCount: 4, NewStart: 1, NewCount: 4,
+		Lines: []string{
+			" ctx",
+			"-old1",
+			"-old2",
+			"+new1",
+			"+new2",
+			" ctx",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		selected   map[int]bool
+		reverse    bool
+		wantHeader string
+		wantLines  []string
+	}{
+		{
+			name:       "stage one removal and one addition",
+			selected:   map[int]bool{1: true, 3: true},
+			wantHeader: "@@ -1,4 +1,4 @@ func main() {",
+			wantLines:  []string{" ctx", "-old1", " old2", "+new1", " ctx"},
+		},
+		{
+			name:       "stage only an addition",
+			selected:   map[int]bool{4: true},
+			wantHeader: "@@ -1,4 +1,5 @@ func main() {",
+			wantLines:  []string{" ctx", " old1", " old2", "+new2", " ctx"},
+		},
+		{
+			name:       "unstage only a removal",
+			selected:   map[int]bool{2: true},
+			reverse:    true,
+			wantHeader: "@@ -1,5 +1,4 @@ func main() {",
+			wantLines:  []string{" ctx", "-old2", " new1", " new2", " ctx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := h.PartialHunk(tt.selected, tt.reverse)
+			if !ok {
+				t.Fatal("PartialHunk() reported no selection")
+			}
+			if got.Header != tt.wantHeader {
+				t.Errorf("Header = %q, want %q", got.Header, tt.wantHeader)
+			}
+			if !slices.Equal(got.Lines, tt.wantLines) {
+				t.Errorf("Lines = %q, want %q", got.Lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestPartialHunk_NoChangeSelected(t *testing.T) {
+	h := Hunk{Header: "@@ -1,2 +1,2 @@", Lines: []string{" ctx", "-a", "+b"}}
+	if _, ok := h.PartialHunk(map[int]bool{0: true}, false); ok {
+		t.Error("expected no partial hunk when only context is selected")
+	}
+}
+
+func TestPartialHunk_NoNewline(t *testing.T) {
+	h := Hunk{
+		Header:   "@@ -1 +1 @@",
+		OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1,
+		Lines: []string{"-a", "+b", `\ No newline at end of file`},
+	}
+	got, ok := h.PartialHunk(map[int]bool{0: true}, false)
+	if !ok {
+		t.Fatal("PartialHunk() reported no selection")
+	}
+	want := []string{"-a"}
+	if !slices.Equal(got.Lines, want) {
+		t.Errorf("Lines = %q, want %q", got.Lines, want)
+	}
+}
+
+func TestSplitHunk(t *testing.T) {
+	h := Hunk{
+		Header:   "@@ -10,9 +10,10 @@ func f() {",
+		OldStart: 10, OldCount: 9, NewStart: 10, NewCount: 10,
+		Lines: []string{
+			" c0",
+			"-a",
+			"+A",
+			"+A2",
+			" c1",
+			" c2",
+			"-b",
+			"+B",
+			" c3",
+			" c4",
+		},
+	}
+
+	got := SplitHunk(h)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 sub-hunks, got %d", len(got))
+	}
+
+	wantHeaders := []string{
+		"@@ -10,4 +10,5 @@ func f() {",
+		"@@ -12,5 +13,5 @@ func f() {",
+	}
+	wantLines := [][]string{
+		{" c0", "-a", "+A", "+A2", " c1", " c2"},
+		{" c1", " c2", "-b", "+B", " c3", " c4"},
+	}
+	for i, sub := range got {
+		if sub.Header != wantHeaders[i] {
+			t.Errorf("sub[%d].Header = %q, want %q", i, sub.Header, wantHeaders[i])
+		}
+		if !slices.Equal(sub.Lines, wantLines[i]) {
+			t.Errorf("sub[%d].Lines = %q, want %q", i, sub.Lines, wantLines[i])
+		}
+	}
+}
+
+func TestSplitHunk_SingleRun(t *testing.T) {
+	h := Hunk{
+		Header:   "@@ -1,3 +1,3 @@",
+		OldStart: 1, OldCount: 3, NewStart: 1, NewCount: 3,
+		Lines: []string{" a", "-b", "+B", " c"},
+	}
+	got := SplitHunk(h)
+	if len(got) != 1 || got[0].Header != h.Header {
+		t.Errorf("SplitHunk() = %+v, want the original hunk", got)
+	}
+}
+
+func TestHunkMoved(t *testing.T) {
+	h := Hunk{
+		Header:   "@@ -3,0 +4,2 @@ func f() {",
+		OldStart: 3, OldCount: 0, NewStart: 4, NewCount: 2,
+		Lines: []string{"+a", "+b"},
+	}
+	got := h.Moved(5, 6)
+	if got.Header != "@@ -5,0 +6,2 @@ func f() {" {
+		t.Errorf("Header = %q", got.Header)
+	}
+	if h.OldStart != 3 {
+		t.Error("Moved() modified the original hunk")
+	}
+}
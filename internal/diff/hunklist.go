@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// HunkRef is a staged or unstaged hunk addressed by ID rather than by
+// position. The ID hashes the file and the hunk's lines, so it survives
+// other hunks being staged around it, and staging the hunk itself, but goes
+// stale as soon as the hunk's content changes.
+type HunkRef struct {
+	ID       string   `json:"id"`
+	Path     string   `json:"path"`
+	Staged   bool     `json:"staged"`
+	OldStart int      `json:"old_start"`
+	OldCount int      `json:"old_count"`
+	NewStart int      `json:"new_start"`
+	NewCount int      `json:"new_count"`
+	Lines    []string `json:"lines"`
+
+	patch string // the hunk under its file header, ready for git apply
+}
+
+// Patch returns the hunk as a patch to git apply --cached: forward to stage
+// an unstaged hunk, reversed to unstage a staged one.
+func (h HunkRef) Patch() string { return h.patch }
+
+// Header is the hunk's @@ line.
+func (h HunkRef) Header() string {
+	return formatHunkHeader(Hunk{OldStart: h.OldStart, OldCount: h.OldCount, NewStart: h.NewStart, NewCount: h.NewCount}, "")
+}
+
+// ListHunks returns the hunks of every tracked file's unstaged changes, or
+// with staged set, of the staged ones, in diff order.
+func ListHunks(repoRoot string, staged bool) ([]HunkRef, error) {
+	raw, err := RawUnifiedDiff(repoRoot, staged, ".")
+	if err != nil {
+		return nil, err
+	}
+	return hunkRefs(raw, staged), nil
+}
+
+// NewFileHunks returns the hunks adding each of the untracked files paths,
+// which stage like any other unstaged hunk.
+func NewFileHunks(repoRoot string, paths []string) ([]HunkRef, error) {
+	var refs []HunkRef
+	for _, path := range paths {
+		raw, err := RawNewFileDiff(repoRoot, path)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, hunkRefs(raw, false)...)
+	}
+	return refs, nil
+}
+
+func hunkRefs(raw string, staged bool) []HunkRef {
+	var refs []HunkRef
+	seen := map[string]int{}
+	for _, fd := range ParseUnifiedDiff(raw) {
+		for _, h := range fd.Hunks {
+			id := hunkID(fd.Path, h.Lines, 0)
+			// Identical hunks in one file are told apart by occurrence.
+			seen[id]++
+			if n := seen[id]; n > 1 {
+				id = hunkID(fd.Path, h.Lines, n)
+			}
+			refs = append(refs, HunkRef{
+				ID:       id,
+				Path:     fd.Path,
+				Staged:   staged,
+				OldStart: h.OldStart,
+				OldCount: h.OldCount,
+				NewStart: h.NewStart,
+				NewCount: h.NewCount,
+				Lines:    h.Lines,
+				patch:    h.Patch(fd.Header),
+			})
+		}
+	}
+	return refs
+}
+
+// hunkID hashes what identifies a hunk, leaving out its line numbers,
+// which shift whenever a hunk above it is staged.
+func hunkID(path string, lines []string, occurrence int) string {
+	sum := sha256.New()
+	sum.Write([]byte(path + "\x00"))
+	for _, line := range lines {
+		sum.Write([]byte(line + "\n"))
+	}
+	if occurrence > 0 {
+		sum.Write([]byte(strconv.Itoa(occurrence)))
+	}
+	return hex.EncodeToString(sum.Sum(nil))[:12]
+}
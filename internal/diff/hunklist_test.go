@@ -0,0 +1,81 @@
+package diff
+
+import "testing"
+
+const twoHunks = `diff --git a/f.txt b/f.txt
+index 1111111..2222222 100644
+--- a/f.txt
++++ b/f.txt
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -20,3 +20,3 @@
+ x
+-y
++Y
+ z
+`
+
+func TestHunkRefs(t *testing.T) {
+	refs := hunkRefs(twoHunks, false)
+	if len(refs) != 2 {
+		t.Fatalf("hunkRefs() returned %d hunks, want 2", len(refs))
+	}
+	h := refs[1]
+	if h.Path != "f.txt" || h.Staged || h.OldStart != 20 || h.NewCount != 3 || len(h.Lines) != 4 {
+		t.Errorf("second hunk = %+v", h)
+	}
+	if want := "diff --git a/f.txt b/f.txt\nindex 1111111..2222222 100644\n--- a/f.txt\n+++ b/f.txt\n@@ -20,3 +20,3 @@\n x\n-y\n+Y\n z\n"; h.Patch() != want {
+		t.Errorf("Patch() = %q, want %q", h.Patch(), want)
+	}
+	if h.Header() != "@@ -20,3 +20,3 @@" {
+		t.Errorf("Header() = %q", h.Header())
+	}
+
+	// Once the first hunk is staged the second sits on the other side and
+	// its line numbers may move, but it's still the same hunk.
+	moved := hunkRefs(`diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -22,3 +22,3 @@
+ x
+-y
++Y
+ z
+`, true)
+	if moved[0].ID != h.ID {
+		t.Errorf("ID changed from %s to %s when the hunk moved", h.ID, moved[0].ID)
+	}
+
+	edited := hunkRefs(`diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -20,3 +20,3 @@
+ x
+-y
++YY
+ z
+`, false)
+	if edited[0].ID == h.ID {
+		t.Error("ID unchanged after the hunk's content changed")
+	}
+}
+
+func TestHunkRefs_IdenticalHunks(t *testing.T) {
+	raw := `diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -1,1 +1,1 @@
+-x
++y
+@@ -9,1 +9,1 @@
+-x
++y
+`
+	refs := hunkRefs(raw, false)
+	if len(refs) != 2 || refs[0].ID == refs[1].ID {
+		t.Errorf("identical hunks got IDs %s and %s, want distinct", refs[0].ID, refs[1].ID)
+	}
+}
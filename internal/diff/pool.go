@@ -0,0 +1,39 @@
+package diff
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Workers caps how many files or hunks render at once. Each render is its
+// own difft process, so one per CPU keeps them all busy.
+var Workers = runtime.NumCPU()
+
+// RenderAll calls render for every index below n on up to Workers
+// goroutines and returns the results in index order. Once ctx is done no
+// more renders start, and those left out keep T's zero value.
+func RenderAll[T any](ctx context.Context, n int, render func(ctx context.Context, i int) T) []T {
+	results := make([]T, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range max(1, min(Workers, n)) {
+		wg.Go(func() {
+			for i := range next {
+				results[i] = render(ctx, i)
+			}
+		})
+	}
+	for i := range n {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
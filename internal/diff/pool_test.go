@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRenderAll(t *testing.T) {
+	var running, peak atomic.Int32
+	got := RenderAll(context.Background(), 20, func(_ context.Context, i int) int {
+		n := running.Add(1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		// Later items finish first, so order must come from the index.
+		time.Sleep(time.Duration(20-i) * 100 * time.Microsecond)
+		running.Add(-1)
+		return i * i
+	})
+
+	want := make([]int, 20)
+	for i := range want {
+		want[i] = i * i
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("RenderAll() = %v, want %v", got, want)
+	}
+	if p := int(peak.Load()); p > Workers {
+		t.Errorf("%d renders ran at once, want at most %d", p, Workers)
+	}
+}
+
+func TestRenderAll_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	got := RenderAll(ctx, 1000, func(ctx context.Context, i int) string {
+		if calls.Add(1) == 1 {
+			cancel()
+		}
+		return "rendered"
+	})
+	if len(got) != 1000 {
+		t.Fatalf("RenderAll() returned %d results, want 1000", len(got))
+	}
+	if n := calls.Load(); n >= 1000 {
+		t.Errorf("RenderAll() kept rendering after cancel: %d calls", n)
+	}
+}
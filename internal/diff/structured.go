@@ -0,0 +1,181 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StructuredDiff is a typed view of the changes to a single file: which
+// lines changed and, where the engine knows, which tokens within them.
+type StructuredDiff struct {
+	Path     string  `json:"path"`
+	Language string  `json:"language"`
+	Status   string  `json:"status"` // unchanged, changed, created, deleted
+	Chunks   []Chunk `json:"chunks"`
+}
+
+// Chunk is a group of nearby changed lines, comparable to a hunk.
+type Chunk struct {
+	Lines []LinePair `json:"lines"`
+}
+
+// LinePair aligns an old line with a new line. Either side is nil when the
+// line only exists on the other side.
+type LinePair struct {
+	Old *LineChange `json:"old,omitempty"`
+	New *LineChange `json:"new,omitempty"`
+}
+
+// LineChange is a single line with the spans that changed on it. Line
+// numbers are 1-based, matching git.
+type LineChange struct {
+	Line    int    `json:"line"`
+	Changes []Span `json:"changes"`
+}
+
+// Span is a changed region of a line. Start and End are byte offsets into
+// the line, End exclusive. Highlight is the syntax class difftastic assigned
+// to the token (keyword, string, comment, ...), or "normal".
+type Span struct {
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	Content   string `json:"content"`
+	Highlight string `json:"highlight"`
+}
+
+// difftJSON mirrors difftastic's --display json output for a single file.
+// Line numbers are 0-based here.
+type difftJSON struct {
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	Status   string `json:"status"`
+	Chunks   [][]struct {
+		LHS *difftSide `json:"lhs"`
+		RHS *difftSide `json:"rhs"`
+	} `json:"chunks"`
+}
+
+type difftSide struct {
+	LineNumber int    `json:"line_number"`
+	Changes    []Span `json:"changes"`
+}
+
+func parseDifftJSON(data []byte) (*StructuredDiff, error) {
+	var raw difftJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse difft json: %w", err)
+	}
+
+	sd := &StructuredDiff{
+		Path:     raw.Path,
+		Language: raw.Language,
+		Status:   raw.Status,
+		Chunks:   make([]Chunk, 0, len(raw.Chunks)),
+	}
+	for _, rc := range raw.Chunks {
+		chunk := Chunk{Lines: make([]LinePair, 0, len(rc))}
+		for _, rl := range rc {
+			chunk.Lines = append(chunk.Lines, LinePair{
+				Old: difftSideToLine(rl.LHS),
+				New: difftSideToLine(rl.RHS),
+			})
+		}
+		sd.Chunks = append(sd.Chunks, chunk)
+	}
+	return sd, nil
+}
+
+func difftSideToLine(s *difftSide) *LineChange {
+	if s == nil {
+		return nil
+	}
+	changes := s.Changes
+	if changes == nil {
+		changes = []Span{}
+	}
+	return &LineChange{Line: s.LineNumber + 1, Changes: changes}
+}
+
+// structuredFromUnified builds a line-level StructuredDiff from a unified
+// diff. Each changed line is reported as a single span covering the whole
+// line; removed and added lines within a run are paired in order.
+func structuredFromUnified(raw, path string) *StructuredDiff {
+	sd := &StructuredDiff{
+		Path:     path,
+		Language: "Text",
+		Status:   "unchanged",
+		Chunks:   []Chunk{},
+	}
+	fds := ParseUnifiedDiff(raw)
+	if len(fds) == 0 {
+		return sd
+	}
+
+	fd := fds[0]
+	switch {
+	case strings.Contains(fd.Header, "\nnew file mode"):
+		sd.Status = "created"
+	case strings.Contains(fd.Header, "\ndeleted file mode"):
+		sd.Status = "deleted"
+	default:
+		sd.Status = "changed"
+	}
+	for _, h := range fd.Hunks {
+		sd.Chunks = append(sd.Chunks, hunkToChunk(h))
+	}
+	return sd
+}
+
+func hunkToChunk(h Hunk) Chunk {
+	chunk := Chunk{Lines: []LinePair{}}
+	oldLine, newLine := h.OldStart, h.NewStart
+	var removed, added []*LineChange
+
+	flush := func() {
+		n := max(len(removed), len(added))
+		for i := 0; i < n; i++ {
+			var pair LinePair
+			if i < len(removed) {
+				pair.Old = removed[i]
+			}
+			if i < len(added) {
+				pair.New = added[i]
+			}
+			chunk.Lines = append(chunk.Lines, pair)
+		}
+		removed, added = nil, nil
+	}
+
+	for _, line := range h.Lines {
+		if len(line) == 0 {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			removed = append(removed, wholeLineChange(oldLine, line[1:]))
+			oldLine++
+		case '+':
+			added = append(added, wholeLineChange(newLine, line[1:]))
+			newLine++
+		case ' ':
+			flush()
+			oldLine++
+			newLine++
+		}
+	}
+	flush()
+	return chunk
+}
+
+func wholeLineChange(n int, content string) *LineChange {
+	return &LineChange{
+		Line: n,
+		Changes: []Span{{
+			Start:     0,
+			End:       len(content),
+			Content:   content,
+			Highlight: "normal",
+		}},
+	}
+}
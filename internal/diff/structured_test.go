@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDifftJSON(t *testing.T) {
+	data := []byte(`{
+  "chunks": [[
+    {"lhs": {"line_number": 2, "changes": [{"start": 8, "end": 11, "content": "old", "highlight": "normal"}]},
+     "rhs": {"line_number": 2, "changes": [{"start": 8, "end": 11, "content": "new", "highlight": "normal"}]}},
+    {"rhs": {"line_number": 3, "changes": [{"start": 0, "end": 6, "content": "return", "highlight": "keyword"}]}}
+  ]],
+  "language": "Go",
+  "path": "/tmp/rift-diff-123/b/main.go",
+  "status": "changed"
+}`)
+
+	got, err := parseDifftJSON(data)
+	if err != nil {
+		t.Fatalf("parseDifftJSON() error = %v", err)
+	}
+	want := &StructuredDiff{
+		Path:     "/tmp/rift-diff-123/b/main.go",
+		Language: "Go",
+		Status:   "changed",
+		Chunks: []Chunk{{Lines: []LinePair{
+			{
+				Old: &LineChange{Line: 3, Changes: []Span{{Start: 8, End: 11, Content: "old", Highlight: "normal"}}},
+				New: &LineChange{Line: 3, Changes: []Span{{Start: 8, End: 11, Content: "new", Highlight: "normal"}}},
+			},
+			{
+				New: &LineChange{Line: 4, Changes: []Span{{Start: 0, End: 6, Content: "return", Highlight: "keyword"}}},
+			},
+		}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDifftJSON() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDifftJSON_Unchanged(t *testing.T) {
+	got, err := parseDifftJSON([]byte(`{"language":"Go","path":"a.go","status":"unchanged"}`))
+	if err != nil {
+		t.Fatalf("parseDifftJSON() error = %v", err)
+	}
+	if got.Status != "unchanged" || got.Chunks == nil || len(got.Chunks) != 0 {
+		t.Errorf("got %+v, want unchanged with empty chunks", got)
+	}
+}
+
+func TestStructuredFromUnified(t *testing.T) {
+	raw := "diff --git a/f.txt b/f.txt\n" +
+		"index abc..def 100644\n" +
+		"--- a/f.txt\n" +
+		"+++ b/f.txt\n" +
+		"@@ -1,4 +1,4 @@\n" +
+		" keep\n" +
+		"-old\n" +
+		"+new\n" +
+		"+extra\n" +
+		" keep\n" +
+		"-gone\n"
+
+	got := structuredFromUnified(raw, "f.txt")
+	if got.Status != "changed" {
+		t.Errorf("Status = %q, want %q", got.Status, "changed")
+	}
+	if len(got.Chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(got.Chunks))
+	}
+	lines := got.Chunks[0].Lines
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 line pairs, got %d", len(lines))
+	}
+	if lines[0].Old == nil || lines[0].Old.Line != 2 || lines[0].New == nil || lines[0].New.Line != 2 {
+		t.Errorf("pair 0 = %+v, want old 2 / new 2", lines[0])
+	}
+	if lines[1].Old != nil || lines[1].New == nil || lines[1].New.Line != 3 {
+		t.Errorf("pair 1 = %+v, want new 3 only", lines[1])
+	}
+	if lines[2].New != nil || lines[2].Old == nil || lines[2].Old.Line != 4 {
+		t.Errorf("pair 2 = %+v, want old 4 only", lines[2])
+	}
+	if span := lines[0].New.Changes[0]; span.Content != "new" || span.End != 3 {
+		t.Errorf("span = %+v, want whole line \"new\"", span)
+	}
+}
+
+func TestStructuredFromUnified_NewFile(t *testing.T) {
+	raw := "diff --git a/n.txt b/n.txt\n" +
+		"new file mode 100644\n" +
+		"--- /dev/null\n" +
+		"+++ b/n.txt\n" +
+		"@@ -0,0 +1 @@\n" +
+		"+hello\n"
+
+	got := structuredFromUnified(raw, "n.txt")
+	if got.Status != "created" {
+		t.Errorf("Status = %q, want %q", got.Status, "created")
+	}
+}
+
+func TestStructuredFromUnified_Empty(t *testing.T) {
+	got := structuredFromUnified("", "f.txt")
+	if got.Status != "unchanged" || len(got.Chunks) != 0 {
+		t.Errorf("got %+v, want unchanged with no chunks", got)
+	}
+}
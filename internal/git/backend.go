@@ -0,0 +1,180 @@
+package git
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Backend reads a repository's state. There are two: go-git reads in
+// process, and the git CLI handles every layout, config and cache git does.
+// Both report the same thing the same way, which the conformance suite in
+// backend_test.go holds them to. Writes always go through the git CLI, so
+// hooks and config apply.
+type Backend interface {
+	Name() string
+	// Status lists every path with staged or unstaged changes, untracked
+	// files included, in no particular order.
+	Status() ([]StatusFile, error)
+	// ChangedFiles is Repo.ChangedFiles, in no particular order.
+	ChangedFiles(staged, untracked bool) ([]ChangedFile, error)
+	// DiffCommits lists the files that differ between two commits, renames
+	// and copies paired by detectRenames.
+	DiffCommits(base, target string) ([]ChangedFile, error)
+	// Log and LogAll are Repo.LogSeq and Repo.LogAllSeq.
+	Log(ref string, maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error]
+	LogAll(maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error]
+	// Branches lists the local branches, in no particular order.
+	Branches() ([]BranchInfo, error)
+	// MergeBase returns the full hash of the best common ancestor of a and b.
+	MergeBase(a, b string) (string, error)
+}
+
+// BackendKind names a backend for the rift.backend git config setting.
+type BackendKind string
+
+const (
+	// BackendAuto, the default, uses the git CLI in repositories of
+	// largeRepoEntries files or more, and go-git otherwise.
+	BackendAuto BackendKind = "auto"
+	// BackendGoGit reads with go-git, falling back to the git CLI for
+	// what go-git can't read. It spares a git process per call, but its
+	// status hashes every file in the worktree.
+	BackendGoGit BackendKind = "go-git"
+	// BackendCLI runs git, whose status takes its stat cache, fsmonitor
+	// and untracked cache into account.
+	BackendCLI BackendKind = "git"
+)
+
+// largeRepoEntries is the index size from which BackendAuto stops using
+// go-git: its status reads every file, so it takes seconds on a large
+// repository where git status takes milliseconds.
+const largeRepoEntries = 5000
+
+// Backend returns the backend the repository reads through, chosen once
+// from rift.backend. Linked worktrees, which go-git gets wrong, always use
+// the git CLI. A rift.backend naming no backend is an error.
+func (r *Repo) Backend() (Backend, error) {
+	r.backendOnce.Do(func() {
+		kind := BackendAuto
+		if out, err := exec.Command("git", "-C", r.root, "config", "--get", "rift.backend").Output(); err == nil {
+			kind = BackendKind(strings.TrimSpace(string(out)))
+		}
+		cli := newCLIBackend(r.root)
+		switch {
+		case kind != BackendAuto && kind != BackendGoGit && kind != BackendCLI:
+			r.backendErr = fmt.Errorf("rift.backend: unknown backend %q (want %s, %s or %s)", kind, BackendAuto, BackendGoGit, BackendCLI)
+		case r.linkedWorktree, kind == BackendCLI:
+			r.backend = cli
+		case kind == BackendGoGit:
+			r.backend = fallbackBackend{goGitBackend{r.repo}, cli}
+		case r.indexEntries() >= largeRepoEntries:
+			r.backend = cli
+		default:
+			r.backend = fallbackBackend{goGitBackend{r.repo}, cli}
+		}
+	})
+	return r.backend, r.backendErr
+}
+
+// indexEntries reads the number of entries from the index's header, or
+// returns 0 if it can't. git finds the index, so GIT_INDEX_FILE, GIT_DIR
+// and gitfiles are all taken into account.
+func (r *Repo) indexEntries() int {
+	out, err := exec.Command("git", "-C", r.root, "rev-parse", "--git-path", "index").Output()
+	if err != nil {
+		return 0
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.root, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	// "DIRC", a 4-byte version, then a 4-byte entry count, big-endian.
+	var header [12]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil || string(header[:4]) != "DIRC" {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(header[8:]))
+}
+
+// fallbackBackend reads through primary, and through fallback when primary
+// fails.
+type fallbackBackend struct {
+	primary, fallback Backend
+}
+
+func (b fallbackBackend) Name() string { return b.primary.Name() }
+
+func (b fallbackBackend) Status() ([]StatusFile, error) {
+	return orElse(b.primary.Status, b.fallback.Status)
+}
+
+func (b fallbackBackend) ChangedFiles(staged, untracked bool) ([]ChangedFile, error) {
+	return orElse(
+		func() ([]ChangedFile, error) { return b.primary.ChangedFiles(staged, untracked) },
+		func() ([]ChangedFile, error) { return b.fallback.ChangedFiles(staged, untracked) })
+}
+
+func (b fallbackBackend) DiffCommits(base, target string) ([]ChangedFile, error) {
+	return orElse(
+		func() ([]ChangedFile, error) { return b.primary.DiffCommits(base, target) },
+		func() ([]ChangedFile, error) { return b.fallback.DiffCommits(base, target) })
+}
+
+func (b fallbackBackend) Branches() ([]BranchInfo, error) {
+	return orElse(b.primary.Branches, b.fallback.Branches)
+}
+
+func (b fallbackBackend) MergeBase(x, y string) (string, error) {
+	return orElse(
+		func() (string, error) { return b.primary.MergeBase(x, y) },
+		func() (string, error) { return b.fallback.MergeBase(x, y) })
+}
+
+func (b fallbackBackend) Log(ref string, maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	return logOrElse(b.primary.Log(ref, maxCount, spec), b.fallback.Log(ref, maxCount, spec))
+}
+
+func (b fallbackBackend) LogAll(maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	return logOrElse(b.primary.LogAll(maxCount, spec), b.fallback.LogAll(maxCount, spec))
+}
+
+func orElse[T any](primary, fallback func() (T, error)) (T, error) {
+	if v, err := primary(); err == nil {
+		return v, nil
+	}
+	return fallback()
+}
+
+// logOrElse streams primary, switching to fallback if primary fails before
+// its first commit.
+func logOrElse(primary, fallback iter.Seq2[CommitInfo, error]) iter.Seq2[CommitInfo, error] {
+	return func(yield func(CommitInfo, error) bool) {
+		n := 0
+		for c, err := range primary {
+			switch {
+			case err != nil && n == 0:
+				fallback(yield)
+				return
+			case err != nil:
+				// Too late to fall back without repeating commits.
+				yield(CommitInfo{}, err)
+				return
+			}
+			n++
+			if !yield(c, nil) {
+				return
+			}
+		}
+	}
+}
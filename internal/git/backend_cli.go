@@ -0,0 +1,263 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// cliBackend reads the repository by running git in root, and its blobs
+// through the repository's shared ObjectReader.
+type cliBackend struct {
+	root string
+}
+
+func newCLIBackend(root string) cliBackend {
+	return cliBackend{root: root}
+}
+
+func (b cliBackend) Name() string { return string(BackendCLI) }
+
+func (b cliBackend) Status() ([]StatusFile, error) {
+	entries, err := b.status(true)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]StatusFile, len(entries))
+	for i, e := range entries {
+		files[i] = StatusFile{Path: e.Path, StagingStatus: e.Staging, WorktreeStatus: e.Worktree}
+	}
+	return files, nil
+}
+
+func (b cliBackend) ChangedFiles(staged, untracked bool) ([]ChangedFile, error) {
+	entries, err := b.status(untracked && !staged)
+	if err != nil {
+		return nil, err
+	}
+	var files []ChangedFile
+	for _, e := range entries {
+		switch {
+		case e.Staging == "Untracked":
+			if !staged {
+				files = append(files, ChangedFile{Path: e.Path, Status: "Added", Untracked: true})
+			}
+		case staged && e.Staging != "":
+			files = append(files, ChangedFile{Path: e.Path, Status: e.Staging})
+		case !staged && e.Worktree != "":
+			files = append(files, ChangedFile{Path: e.Path, Status: e.Worktree})
+		}
+	}
+	if staged {
+		files = detectRenames(files, b.content("HEAD:"), b.content(":"))
+	}
+	return files, nil
+}
+
+// status runs git status, each path on its own: renames are paired by
+// detectRenames, as go-git's are.
+func (b cliBackend) status(untracked bool) ([]statusEntry, error) {
+	args := []string{"-C", b.root, "status", "--porcelain=v2", "-z", "--no-renames"}
+	if untracked {
+		args = append(args, "--untracked-files=all")
+	} else {
+		args = append(args, "--untracked-files=no")
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	return parseStatusV2(out)
+}
+
+// content reads paths from the side of a diff prefix names: "HEAD:" for
+// HEAD, ":" for the index, "main:" for a commit.
+func (b cliBackend) content(prefix string) contentFunc {
+	return func(path string) ([]byte, error) {
+		return Objects(b.root).Read(context.Background(), prefix+path)
+	}
+}
+
+func (b cliBackend) DiffCommits(base, target string) ([]ChangedFile, error) {
+	for _, ref := range []string{base, target} {
+		if err := verifyRef(b.root, ref); err != nil {
+			return nil, err
+		}
+	}
+	out, err := exec.Command("git", "-C", b.root, "diff", "--name-status", "-z", "--no-renames", base, target).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --name-status %s %s: %w", base, target, err)
+	}
+	var files []ChangedFile
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		if status := porcelainCode(fields[i][0]); status != "" {
+			files = append(files, ChangedFile{Path: fields[i+1], Status: status})
+		}
+	}
+	return detectRenames(files, b.content(base+":"), b.content(target+":")), nil
+}
+
+// Log reports a ref that doesn't exist as a *RefError.
+func (b cliBackend) Log(ref string, maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	return func(yield func(CommitInfo, error) bool) {
+		for c, err := range b.log(ref, maxCount, false, spec) {
+			if err != nil {
+				if verr := verifyRef(b.root, ref); verr != nil {
+					err = verr
+				}
+			}
+			if !yield(c, err) {
+				return
+			}
+		}
+	}
+}
+
+func (b cliBackend) LogAll(maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	return b.log("", maxCount, true, spec)
+}
+
+// log runs git log. Records are parsed as git writes them, and git is
+// killed if the loop stops early.
+func (b cliBackend) log(ref string, maxCount int, all bool, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	args := []string{"-C", b.root, "log", logFormat}
+	if maxCount > 0 {
+		args = append(args, "-n", strconv.Itoa(maxCount))
+	}
+	if all {
+		args = append(args, "--branches", "--remotes")
+	} else if ref != "" {
+		args = append(args, ref)
+	}
+	if !spec.IsEmpty() {
+		args = append(args, "--")
+		args = append(args, spec.Args()...)
+	}
+
+	return func(yield func(CommitInfo, error) bool) {
+		cmd := exec.Command("git", args...)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			yield(CommitInfo{}, fmt.Errorf("git log: %w", err))
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			yield(CommitInfo{}, fmt.Errorf("git log: %w", err))
+			return
+		}
+
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+		scanner.Split(scanNULRecords)
+		for scanner.Scan() {
+			ci, ok := parseGitLogRecord(scanner.Text())
+			if !ok {
+				continue
+			}
+			if !yield(ci, nil) {
+				cmd.Process.Kill()
+				cmd.Wait()
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			yield(CommitInfo{}, fmt.Errorf("git log: %w", err))
+			return
+		}
+		if err := cmd.Wait(); err != nil {
+			yield(CommitInfo{}, fmt.Errorf("git log: %w", err))
+		}
+	}
+}
+
+func (b cliBackend) Branches() ([]BranchInfo, error) {
+	out, err := exec.Command("git", "-C", b.root, "for-each-ref",
+		"--format=%(HEAD)%1e%(refname:lstrip=2)%1e%(authordate:format:%Y-%m-%d %H:%M)%1e%(contents)%00",
+		"refs/heads").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+	upstreams, err := b.upstreams()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := []BranchInfo{}
+	for record := range strings.SplitSeq(string(out), "\x00") {
+		record = strings.TrimPrefix(record, "\n")
+		if record == "" {
+			continue
+		}
+		f := strings.SplitN(record, "\x1e", 4)
+		if len(f) != 4 {
+			return nil, fmt.Errorf("git for-each-ref: malformed record %q", record)
+		}
+		branches = append(branches, BranchInfo{
+			Name:    f[1],
+			Current: f[0] == "*",
+			Remote:  upstreams[f[1]],
+			Date:    f[2],
+			Message: firstLine(f[3]),
+		})
+	}
+	return branches, nil
+}
+
+// upstreams maps branches to their upstream as trackingRemote writes it,
+// from branch.<name>.remote and .merge alone. %(upstream) also needs the
+// remote's fetch refspec, which a remote added by hand may not have.
+func (b cliBackend) upstreams() (map[string]string, error) {
+	out, err := exec.Command("git", "-C", b.root, "config", "-z", "--get-regexp", `^branch\..*\.(remote|merge)$`).Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return nil, nil // no branch has either
+	}
+	if err != nil {
+		return nil, fmt.Errorf("git config: %w", err)
+	}
+
+	remotes, merges := map[string]string{}, map[string]string{}
+	for record := range strings.SplitSeq(string(out), "\x00") {
+		key, value, _ := strings.Cut(record, "\n")
+		i := strings.LastIndexByte(key, '.')
+		if i < len("branch.") {
+			continue
+		}
+		name := key[len("branch."):i]
+		if key[i+1:] == "remote" {
+			remotes[name] = value
+		} else {
+			merges[name] = value
+		}
+	}
+	upstreams := map[string]string{}
+	for name, remote := range remotes {
+		if merge := merges[name]; remote != "" && merge != "" {
+			upstreams[name] = remote + "/" + plumbing.ReferenceName(merge).Short()
+		}
+	}
+	return upstreams, nil
+}
+
+func (b cliBackend) MergeBase(x, y string) (string, error) {
+	for _, ref := range []string{x, y} {
+		if err := verifyRef(b.root, ref); err != nil {
+			return "", err
+		}
+	}
+	out, err := exec.Command("git", "-C", b.root, "merge-base", x, y).Output()
+	if err != nil {
+		return "", fmt.Errorf("git merge-base %s %s: %w", x, y, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
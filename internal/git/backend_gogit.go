@@ -0,0 +1,456 @@
+package git
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+)
+
+// goGitBackend reads the repository in process.
+type goGitBackend struct {
+	repo *gogit.Repository
+}
+
+func (b goGitBackend) Name() string { return string(BackendGoGit) }
+
+func (b goGitBackend) status() (gogit.Status, map[string]bool, error) {
+	wt, err := b.repo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get worktree: %w", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get status: %w", err)
+	}
+	unmerged, err := b.unmerged()
+	if err != nil {
+		return nil, nil, err
+	}
+	return status, unmerged, nil
+}
+
+// unmerged returns the paths with conflicts in the index, which go-git's
+// status reports as merely modified.
+func (b goGitBackend) unmerged() (map[string]bool, error) {
+	idx, err := b.repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+	paths := map[string]bool{}
+	for _, e := range idx.Entries {
+		if e.Stage != 0 {
+			paths[e.Name] = true
+		}
+	}
+	return paths, nil
+}
+
+func (b goGitBackend) Status() ([]StatusFile, error) {
+	status, unmerged, err := b.status()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []StatusFile
+	for path, s := range status {
+		staging := statusCodeToString(s.Staging)
+		worktree := statusCodeToString(s.Worktree)
+		if unmerged[path] {
+			staging, worktree = "Unmerged", "Unmerged"
+		}
+		if staging == "" && worktree == "" {
+			continue
+		}
+		files = append(files, StatusFile{
+			Path:           path,
+			StagingStatus:  staging,
+			WorktreeStatus: worktree,
+		})
+	}
+	return files, nil
+}
+
+func (b goGitBackend) ChangedFiles(staged, untracked bool) ([]ChangedFile, error) {
+	status, unmerged, err := b.status()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []ChangedFile
+	for path, s := range status {
+		var code string
+		switch {
+		case unmerged[path]:
+			code = "Unmerged"
+		case staged:
+			if s.Staging == '?' || s.Staging == ' ' || s.Staging == 0 {
+				continue
+			}
+			code = statusCodeToString(s.Staging)
+		case s.Worktree == '?':
+			if untracked {
+				files = append(files, ChangedFile{Path: path, Status: "Added", Untracked: true})
+			}
+			continue
+		default:
+			code = statusCodeToString(s.Worktree)
+		}
+		if code == "" {
+			continue
+		}
+		files = append(files, ChangedFile{Path: path, Status: code})
+	}
+
+	// Unstaged additions are untracked, so only the index can hold renames.
+	if staged {
+		oldContent, err := b.headContent()
+		if err != nil {
+			return files, nil
+		}
+		newContent, err := b.indexContent()
+		if err != nil {
+			return files, nil
+		}
+		files = detectRenames(files, oldContent, newContent)
+	}
+
+	return files, nil
+}
+
+func (b goGitBackend) DiffCommits(baseRef, targetRef string) ([]ChangedFile, error) {
+	baseCommit, err := b.resolveCommit(baseRef)
+	if err != nil {
+		return nil, &RefError{Ref: baseRef, Err: err}
+	}
+
+	targetCommit, err := b.resolveCommit(targetRef)
+	if err != nil {
+		return nil, &RefError{Ref: targetRef, Err: err}
+	}
+
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("get base tree: %w", err)
+	}
+
+	targetTree, err := targetCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("get target tree: %w", err)
+	}
+
+	// Rename detection is done by detectRenames rather than go-git so both
+	// backends report the same pairs and similarity scores.
+	changes, err := object.DiffTreeWithOptions(context.Background(), baseTree, targetTree, nil)
+	if err != nil {
+		return nil, fmt.Errorf("diff trees: %w", err)
+	}
+
+	var files []ChangedFile
+	for _, c := range changes {
+		name := c.To.Name
+		if name == "" {
+			name = c.From.Name
+		}
+		files = append(files, ChangedFile{
+			Path:   name,
+			Status: diffActionString(c),
+		})
+	}
+
+	return detectRenames(files, treeContent(baseTree), treeContent(targetTree)), nil
+}
+
+func (b goGitBackend) Log(ref string, maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	return func(yield func(CommitInfo, error) bool) {
+		h, err := b.repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			yield(CommitInfo{}, &RefError{Ref: ref, Err: err})
+			return
+		}
+		if _, err := b.log(*h, maxCount, spec, yield); err != nil {
+			yield(CommitInfo{}, err)
+		}
+	}
+}
+
+func (b goGitBackend) LogAll(maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	return func(yield func(CommitInfo, error) bool) {
+		if err := b.logAll(maxCount, spec, yield); err != nil {
+			yield(CommitInfo{}, err)
+		}
+	}
+}
+
+// log walks the history from one commit, passing commits to yield until
+// maxCount is reached or yield asks to stop. It returns how many commits
+// were yielded.
+func (b goGitBackend) log(from plumbing.Hash, maxCount int, spec Pathspec, yield func(CommitInfo, error) bool) (int, error) {
+	opts := &gogit.LogOptions{
+		From:  from,
+		Order: gogit.LogOrderCommitterTime,
+	}
+	if !spec.IsEmpty() {
+		opts.PathFilter = spec.Match
+	}
+
+	commitIter, err := b.repo.Log(opts)
+	if err != nil {
+		return 0, err
+	}
+	defer commitIter.Close()
+
+	n := 0
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if maxCount > 0 && n >= maxCount {
+			return storer.ErrStop
+		}
+		n++
+		if !yield(commitToInfo(c), nil) {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return n, err
+}
+
+// logAll walks the history of every local and remote branch at once, newest
+// committer time first as git log does, so maxCount keeps the newest
+// commits across all of them.
+func (b goGitBackend) logAll(maxCount int, spec Pathspec, yield func(CommitInfo, error) bool) error {
+	refs, err := b.repo.References()
+	if err != nil {
+		return err
+	}
+	var walk commitQueue
+	seen := map[plumbing.Hash]bool{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || (!ref.Name().IsBranch() && !ref.Name().IsRemote()) || seen[ref.Hash()] {
+			return nil
+		}
+		c, err := b.repo.CommitObject(ref.Hash())
+		if err != nil {
+			return nil // not a commit
+		}
+		seen[c.Hash] = true
+		walk.push(c)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for n := 0; walk.Len() > 0 && (maxCount <= 0 || n < maxCount); {
+		c := walk.pop()
+		for _, h := range c.ParentHashes {
+			if seen[h] {
+				continue
+			}
+			seen[h] = true
+			parent, err := b.repo.CommitObject(h)
+			if err != nil {
+				return err
+			}
+			walk.push(parent)
+		}
+		if !spec.IsEmpty() {
+			ok, err := touches(c, spec)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		n++
+		if !yield(commitToInfo(c), nil) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// touches reports whether c changes a path spec selects. A merge has to
+// differ from every parent there, as git log's history simplification
+// has it; a root commit is compared with an empty tree.
+func touches(c *object.Commit, spec Pathspec) (bool, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return false, err
+	}
+	parents := []*object.Tree{nil}
+	if c.NumParents() > 0 {
+		parents = parents[:0]
+		err := c.Parents().ForEach(func(p *object.Commit) error {
+			t, err := p.Tree()
+			parents = append(parents, t)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, parent := range parents {
+		changes, err := object.DiffTree(parent, tree)
+		if err != nil {
+			return false, err
+		}
+		if !slices.ContainsFunc(changes, func(ch *object.Change) bool {
+			return spec.Match(ch.From.Name) || spec.Match(ch.To.Name)
+		}) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// commitQueue orders commits newest committer time first, and commits with
+// the same time in the order they were queued, as git's walk does.
+type commitQueue struct {
+	items []queuedCommit
+	next  int
+}
+
+type queuedCommit struct {
+	commit *object.Commit
+	seq    int
+}
+
+func (q *commitQueue) Len() int { return len(q.items) }
+func (q *commitQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if !a.commit.Committer.When.Equal(b.commit.Committer.When) {
+		return a.commit.Committer.When.After(b.commit.Committer.When)
+	}
+	return a.seq < b.seq
+}
+func (q *commitQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *commitQueue) Push(x any)    { q.items = append(q.items, x.(queuedCommit)) }
+func (q *commitQueue) Pop() any {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
+
+func (q *commitQueue) push(c *object.Commit) {
+	heap.Push(q, queuedCommit{commit: c, seq: q.next})
+	q.next++
+}
+
+func (q *commitQueue) pop() *object.Commit {
+	return heap.Pop(q).(queuedCommit).commit
+}
+
+func (b goGitBackend) Branches() ([]BranchInfo, error) {
+	head, err := b.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("get HEAD: %w", err)
+	}
+
+	cfg, err := b.repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	refs, err := b.repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("list branches: %w", err)
+	}
+	defer refs.Close()
+
+	branches := []BranchInfo{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+
+		commit, err := b.repo.CommitObject(ref.Hash())
+		if err != nil {
+			return fmt.Errorf("resolve commit for %s: %w", name, err)
+		}
+
+		branches = append(branches, BranchInfo{
+			Name:    name,
+			Current: ref.Hash() == head.Hash() && ref.Name() == head.Name(),
+			Remote:  trackingRemote(cfg, name),
+			Date:    commit.Author.When.Format("2006-01-02 15:04"),
+			Message: firstLine(commit.Message),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate branches: %w", err)
+	}
+	return branches, nil
+}
+
+func (b goGitBackend) MergeBase(x, y string) (string, error) {
+	xc, err := b.resolveCommit(x)
+	if err != nil {
+		return "", &RefError{Ref: x, Err: err}
+	}
+	yc, err := b.resolveCommit(y)
+	if err != nil {
+		return "", &RefError{Ref: y, Err: err}
+	}
+	bases, err := xc.MergeBase(yc)
+	if err != nil {
+		return "", fmt.Errorf("merge base of %s and %s: %w", x, y, err)
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("%s and %s have no merge base", x, y)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+func (b goGitBackend) resolveCommit(ref string) (*object.Commit, error) {
+	h, err := b.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, err
+	}
+	return b.repo.CommitObject(*h)
+}
+
+func (b goGitBackend) headContent() (contentFunc, error) {
+	head, err := b.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := b.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return treeContent(tree), nil
+}
+
+func (b goGitBackend) indexContent() (contentFunc, error) {
+	idx, err := b.repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	return func(path string) ([]byte, error) {
+		e, err := idx.Entry(path)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := b.repo.BlobObject(e.Hash)
+		if err != nil {
+			return nil, err
+		}
+		rd, err := blob.Reader()
+		if err != nil {
+			return nil, err
+		}
+		defer rd.Close()
+		return io.ReadAll(rd)
+	}, nil
+}
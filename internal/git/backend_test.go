@@ -0,0 +1,288 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v6"
+)
+
+func backends(repo *Repo) map[string]Backend {
+	return map[string]Backend{
+		"go-git": goGitBackend{repo.repo},
+		"git":    newCLIBackend(repo.root),
+	}
+}
+
+// conformanceRepo builds the fixture both backends are checked against:
+// history on two branches with a remote-tracking ref, commits dated in a
+// zone other than UTC, a rename and a copy, and a worktree with every kind
+// of change, including paths git would quote.
+func conformanceRepo(t *testing.T) *Repo {
+	t.Helper()
+	dir := t.TempDir()
+	setCommitIdentity(t)
+	commit := func(date, msg string) {
+		t.Helper()
+		t.Setenv("GIT_AUTHOR_DATE", date)
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		runGit(t, dir, msg, "commit", "-q", "-F", "-")
+	}
+
+	runGit(t, dir, "", "init", "-q", "-b", "main")
+	guide := strings.Repeat("A line of the guide.\n", 20)
+	main := "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	writeFile(t, dir, "README.md", "# fixture\n")
+	writeFile(t, dir, "docs/guide.md", guide)
+	writeFile(t, dir, "src/main.go", main)
+	runGit(t, dir, "", "add", "-A")
+	commit("2025-03-01T10:00:00+02:00", "Initial commit")
+
+	writeFile(t, dir, "src/main.go", main+"\nfunc helper() {}\n")
+	runGit(t, dir, "", "add", "src/main.go")
+	commit("2025-03-02T11:30:00+02:00", "Add a helper\nspanning two lines\n\nThe body explains\nwhy.")
+	runGit(t, dir, "", "update-ref", "refs/remotes/origin/main", "HEAD")
+	runGit(t, dir, "", "config", "branch.main.remote", "origin")
+	runGit(t, dir, "", "config", "branch.main.merge", "refs/heads/main")
+
+	runGit(t, dir, "", "checkout", "-q", "-b", "feature")
+	writeFile(t, dir, "feature.txt", "feature\n")
+	runGit(t, dir, "", "add", "feature.txt")
+	commit("2025-03-03T09:15:00+02:00", "Add the feature")
+
+	runGit(t, dir, "", "checkout", "-q", "main")
+	runGit(t, dir, "", "mv", "docs/guide.md", "docs/manual.md")
+	writeFile(t, dir, "src/main.go", main+"\nfunc helper() {}\n\nfunc other() {}\n")
+	writeFile(t, dir, "src/copy.go", main+"\nfunc helper() {}\n")
+	runGit(t, dir, "", "add", "-A")
+	commit("2025-03-04T16:45:00+02:00", "Rename the guide and copy main")
+
+	writeFile(t, dir, "README.md", "# fixture\n\nChanged.\n")
+	writeFile(t, dir, "new file.txt", "new\n")
+	runGit(t, dir, "", "add", "new file.txt")
+	runGit(t, dir, "", "mv", "src/copy.go", "src/copy of main.go")
+	writeFile(t, dir, `notes/"quoted".txt`, "quoted\n")
+	writeFile(t, dir, "café.txt", "unicode\n")
+	writeFile(t, dir, "debug.log", "ignored\n")
+
+	r, err := gogit.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	return &Repo{repo: r, root: dir}
+}
+
+func revParse(t *testing.T, dir, rev string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "rev-parse", rev).Output()
+	if err != nil {
+		t.Fatalf("git rev-parse %s: %v", rev, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// TestBackendConformance holds both backends to the same answers on the
+// same repository. Results are sorted where the interface leaves order open.
+func TestBackendConformance(t *testing.T) {
+	repo := conformanceRepo(t)
+	short := func(rev string) string { return revParse(t, repo.root, rev)[:7] }
+	body := "spanning two lines\n\nThe body explains\nwhy."
+	rename := CommitInfo{Hash: short("main"), Author: "Test", Date: "2025-03-04 16:45", Message: "Rename the guide and copy main"}
+	helper := CommitInfo{Hash: short("main~"), Author: "Test", Date: "2025-03-02 11:30", Message: "Add a helper", Body: body}
+	initial := CommitInfo{Hash: short("main~2"), Author: "Test", Date: "2025-03-01 10:00", Message: "Initial commit"}
+	feature := CommitInfo{Hash: short("feature"), Author: "Test", Date: "2025-03-03 09:15", Message: "Add the feature"}
+	src, err := ParsePathspec("", []string{"src"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unstaged := []ChangedFile{{Path: "README.md", Status: "Modified"}}
+	untracked := append(slices.Clone(unstaged),
+		ChangedFile{Path: "café.txt", Status: "Added", Untracked: true},
+		ChangedFile{Path: `notes/"quoted".txt`, Status: "Added", Untracked: true})
+	staged := []ChangedFile{
+		{Path: "new file.txt", Status: "Added"},
+		{Path: "src/copy of main.go", OldPath: "src/copy.go", Status: "Renamed", Similarity: 100},
+	}
+
+	tests := []struct {
+		name string
+		got  func(Backend) (any, error)
+		want any
+	}{
+		{"Status", func(b Backend) (any, error) {
+			files, err := b.Status()
+			sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+			return files, err
+		}, []StatusFile{
+			{Path: "README.md", WorktreeStatus: "Modified"},
+			{Path: "café.txt", StagingStatus: "Untracked", WorktreeStatus: "Untracked"},
+			{Path: "new file.txt", StagingStatus: "Added"},
+			{Path: `notes/"quoted".txt`, StagingStatus: "Untracked", WorktreeStatus: "Untracked"},
+			{Path: "src/copy of main.go", StagingStatus: "Added"},
+			{Path: "src/copy.go", StagingStatus: "Deleted"},
+		}},
+		{"ChangedFiles", changedFiles(false, false), unstaged},
+		{"ChangedFiles/untracked", changedFiles(false, true), untracked},
+		{"ChangedFiles/staged", changedFiles(true, false), staged},
+		{"ChangedFiles/staged+untracked", changedFiles(true, true), staged},
+		{"DiffCommits", func(b Backend) (any, error) {
+			files, err := b.DiffCommits("feature", "main")
+			sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+			return files, err
+		}, []ChangedFile{
+			{Path: "docs/manual.md", OldPath: "docs/guide.md", Status: "Renamed", Similarity: 100},
+			{Path: "feature.txt", Status: "Deleted"},
+			{Path: "src/copy.go", OldPath: "src/main.go", Status: "Copied", Similarity: 100},
+			{Path: "src/main.go", Status: "Modified"},
+		}},
+		{"Log", func(b Backend) (any, error) {
+			return collectCommits(b.Log("HEAD", 0, Pathspec{}))
+		}, []CommitInfo{rename, helper, initial}},
+		{"Log/maxCount", func(b Backend) (any, error) {
+			return collectCommits(b.Log("main", 2, Pathspec{}))
+		}, []CommitInfo{rename, helper}},
+		{"Log/pathspec", func(b Backend) (any, error) {
+			return collectCommits(b.Log("main", 0, src))
+		}, []CommitInfo{rename, helper, initial}},
+		{"Log/feature", func(b Backend) (any, error) {
+			return collectCommits(b.Log("feature", 0, Pathspec{}))
+		}, []CommitInfo{feature, helper, initial}},
+		{"LogAll", func(b Backend) (any, error) {
+			return collectCommits(b.LogAll(0, Pathspec{}))
+		}, []CommitInfo{rename, feature, helper, initial}},
+		// The limit keeps the newest commits across branches, not the first
+		// ones found on one of them.
+		{"LogAll/maxCount", func(b Backend) (any, error) {
+			return collectCommits(b.LogAll(2, Pathspec{}))
+		}, []CommitInfo{rename, feature}},
+		{"LogAll/pathspec", func(b Backend) (any, error) {
+			return collectCommits(b.LogAll(0, src))
+		}, []CommitInfo{rename, helper, initial}},
+		{"Branches", func(b Backend) (any, error) {
+			branches, err := b.Branches()
+			sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+			return branches, err
+		}, []BranchInfo{
+			{Name: "feature", Date: "2025-03-03 09:15", Message: "Add the feature"},
+			{Name: "main", Current: true, Remote: "origin/main", Date: "2025-03-04 16:45", Message: "Rename the guide and copy main"},
+		}},
+		{"MergeBase", func(b Backend) (any, error) {
+			return b.MergeBase("main", "feature")
+		}, revParse(t, repo.root, "main~")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, b := range backends(repo) {
+				got, err := tt.got(b)
+				if err != nil {
+					t.Fatalf("%s: error = %v", name, err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s:\n got %+v\nwant %+v", name, got, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("BadRef", func(t *testing.T) {
+		for name, b := range backends(repo) {
+			var refErr *RefError
+			if _, err := collectCommits(b.Log("no-such-ref", 0, Pathspec{})); !errors.As(err, &refErr) {
+				t.Errorf("%s: Log() error = %v, want a *RefError", name, err)
+			}
+			if _, err := b.DiffCommits("main", "no-such-ref"); !errors.As(err, &refErr) {
+				t.Errorf("%s: DiffCommits() error = %v, want a *RefError", name, err)
+			}
+			if _, err := b.MergeBase("no-such-ref", "main"); !errors.As(err, &refErr) {
+				t.Errorf("%s: MergeBase() error = %v, want a *RefError", name, err)
+			}
+		}
+	})
+}
+
+func changedFiles(staged, untracked bool) func(Backend) (any, error) {
+	return func(b Backend) (any, error) {
+		files, err := b.ChangedFiles(staged, untracked)
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+		return files, err
+	}
+}
+
+func TestRepoBackend(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		entries    int
+		indexFile  bool // the index is in GIT_INDEX_FILE
+		want       string
+		wantErrSub string
+	}{
+		{name: "small", entries: 1, want: "go-git"},
+		{name: "large", entries: largeRepoEntries, want: "git"},
+		{name: "large GIT_INDEX_FILE", entries: largeRepoEntries, indexFile: true, want: "git"},
+		{name: "auto", config: "auto", entries: largeRepoEntries, want: "git"},
+		{name: "go-git", config: "go-git", entries: largeRepoEntries, want: "go-git"},
+		{name: "git", config: "git", entries: 1, want: "git"},
+		{name: "unknown", config: "gogit", entries: 1, wantErrSub: `unknown backend "gogit"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupTestRepo(t)
+			if tt.config != "" {
+				runGit(t, repo.root, "", "config", "rift.backend", tt.config)
+			}
+			if tt.indexFile {
+				t.Setenv("GIT_INDEX_FILE", filepath.Join(t.TempDir(), "index"))
+				runGit(t, repo.root, "", "read-tree", "HEAD")
+			}
+			if tt.entries > 1 {
+				// update-index --index-info adds entries without files to
+				// back them, which is all the heuristic reads.
+				var info strings.Builder
+				blob := revParse(t, repo.root, "HEAD:README.md")
+				for i := range tt.entries {
+					fmt.Fprintf(&info, "100644 %s\tf%d\n", blob, i)
+				}
+				runGit(t, repo.root, info.String(), "update-index", "--index-info")
+			}
+			b, err := repo.Backend()
+			if tt.wantErrSub != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrSub) {
+					t.Errorf("Backend() error = %v, want one containing %q", err, tt.wantErrSub)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Backend() error = %v", err)
+			}
+			if got := b.Name(); got != tt.want {
+				t.Errorf("Backend() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// go-git misreads a linked worktree's index, whatever the config says.
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+	runGit(t, repo.root, "", "config", "rift.backend", "go-git")
+	path := filepath.Join(t.TempDir(), "linked")
+	runGit(t, repo.root, "", "worktree", "add", "-q", "-b", "linked", path)
+	t.Chdir(path)
+	linked, err := OpenRepo()
+	if err != nil {
+		t.Fatalf("OpenRepo() error = %v", err)
+	}
+	if b, err := linked.Backend(); err != nil || b.Name() != "git" {
+		t.Errorf("linked worktree: Backend() = %v, %v; want git", b, err)
+	}
+}
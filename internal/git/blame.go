@@ -0,0 +1,48 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Blame attributes each line of path at HEAD to the full hash of the commit
+// that last changed it. Line numbers are 1-based.
+func (r *Repo) Blame(path string) (map[int]string, error) {
+	out, err := exec.Command("git", "-C", r.root, "blame", "--porcelain", "HEAD", "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w", path, err)
+	}
+	return parseBlamePorcelain(string(out)), nil
+}
+
+// parseBlamePorcelain reads the "<hash> <orig-line> <final-line> [<count>]"
+// header that precedes every line in git blame --porcelain output.
+func parseBlamePorcelain(out string) map[int]string {
+	lines := map[int]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" || line[0] == '\t' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || len(fields[0]) != 40 || !isHex(fields[0]) {
+			continue
+		}
+		n, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		lines[n] = fields[0]
+	}
+	return lines
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
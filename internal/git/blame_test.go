@@ -0,0 +1,48 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBlamePorcelain(t *testing.T) {
+	a := "1111111111111111111111111111111111111111"
+	b := "2222222222222222222222222222222222222222"
+	out := a + " 1 1 2\n" +
+		"author Test\n" +
+		"summary first\n" +
+		"filename f.txt\n" +
+		"\tone\n" +
+		a + " 2 2\n" +
+		"\ttwo\n" +
+		b + " 1 3 1\n" +
+		"author Test\n" +
+		"summary second\n" +
+		"filename f.txt\n" +
+		"\t" + a + " 9 9 looks like a header\n"
+
+	got := parseBlamePorcelain(out)
+	want := map[int]string{1: a, 2: a, 3: b}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBlamePorcelain() = %v, want %v", got, want)
+	}
+}
+
+func TestBlame(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	first := commitFile(t, repo, "f.txt", "a\nb\n", "Add f")
+	second := commitFile(t, repo, "f.txt", "a\nB\nc\n", "Change f")
+
+	got, err := repo.Blame("f.txt")
+	if err != nil {
+		t.Fatalf("Blame() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Blame() = %v, want 3 lines", got)
+	}
+	if got[1][:7] != first.Hash || got[2][:7] != second.Hash || got[3][:7] != second.Hash {
+		t.Errorf("Blame() = %v, want line 1 from %s and lines 2-3 from %s", got, first.Hash, second.Hash)
+	}
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit records the index as a new commit, or amends HEAD, by shelling out
+// to git so hooks and signing config apply. An empty message is only valid
+// when amending and keeps HEAD's message.
+func (r *Repo) Commit(message string, amend bool) (CommitInfo, error) {
+	args := []string{"-C", r.root, "commit"}
+	if amend {
+		args = append(args, "--amend")
+	}
+	if message == "" {
+		args = append(args, "--no-edit")
+	} else {
+		args = append(args, "-F", "-")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return CommitInfo{}, fmt.Errorf("git commit: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+
+	return r.headCommit()
+}
+
+// headCommit reads back HEAD after git has moved it.
+func (r *Repo) headCommit() (CommitInfo, error) {
+	commits, err := r.Log("HEAD", 1, Pathspec{})
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("read new commit: %w", err)
+	}
+	if len(commits) == 0 {
+		return CommitInfo{}, fmt.Errorf("read new commit: HEAD not found")
+	}
+	return commits[0], nil
+}
+
+// HeadMessage returns HEAD's full commit message, subject and body.
+func (r *Repo) HeadMessage() (string, error) {
+	commits, err := r.Log("HEAD", 1, Pathspec{})
+	if err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("HEAD not found")
+	}
+	c := commits[0]
+	if c.Body == "" {
+		return c.Message, nil
+	}
+	return c.Message + "\n\n" + c.Body, nil
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setCommitIdentity lets the git CLI commit without relying on the
+// machine's global config.
+func setCommitIdentity(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(k+"_NAME", "Test")
+		t.Setenv(k+"_EMAIL", "test@test.com")
+	}
+}
+
+func stageFile(t *testing.T, repo *Repo, name, content string) {
+	t.Helper()
+	writeFile(t, repo.root, name, content)
+	wt, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	stageFile(t, repo, "main.go", "package main\n")
+	info, err := repo.Commit("Add main\n\nWith a body.", false)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if info.Message != "Add main" || info.Body != "With a body." {
+		t.Errorf("Commit() = %+v, want subject %q and body %q", info, "Add main", "With a body.")
+	}
+
+	msg, err := repo.HeadMessage()
+	if err != nil {
+		t.Fatalf("HeadMessage() error = %v", err)
+	}
+	if msg != "Add main\n\nWith a body." {
+		t.Errorf("HeadMessage() = %q", msg)
+	}
+}
+
+func TestCommit_Amend(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	stageFile(t, repo, "a.txt", "a\n")
+	first, err := repo.Commit("Add a", false)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	stageFile(t, repo, "b.txt", "b\n")
+	amended, err := repo.Commit("", true)
+	if err != nil {
+		t.Fatalf("Commit(amend) error = %v", err)
+	}
+	if amended.Hash == first.Hash || amended.Message != "Add a" {
+		t.Errorf("Commit(amend) = %+v, want a new hash keeping message %q", amended, "Add a")
+	}
+
+	commits, err := repo.Log("HEAD", 0, Pathspec{})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("expected amend to replace HEAD, got %d commits", len(commits))
+	}
+}
+
+func TestCommit_HookRejects(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	hook := filepath.Join(repo.root, ".git", "hooks", "pre-commit")
+	if err := os.MkdirAll(filepath.Dir(hook), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\necho rejected >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+
+	stageFile(t, repo, "a.txt", "a\n")
+	if _, err := repo.Commit("Add a", false); err == nil {
+		t.Fatal("Commit() error = nil, want pre-commit hook failure")
+	}
+}
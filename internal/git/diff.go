@@ -2,173 +2,148 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 
 	gogit "github.com/go-git/go-git/v6"
-	"github.com/go-git/go-git/v6/plumbing"
 	"github.com/go-git/go-git/v6/plumbing/object"
 )
 
 type ChangedFile struct {
-	Path   string `json:"path"`
-	Status string `json:"status"`
+	Path       string `json:"path"`
+	OldPath    string `json:"old_path,omitempty"` // source path for Renamed/Copied
+	Status     string `json:"status"`
+	Similarity int    `json:"similarity,omitempty"` // percent, for Renamed/Copied
+	Untracked  bool   `json:"untracked,omitempty"`  // Added in the worktree, unknown to git
 }
 
-func (r *Repo) ChangedFiles(staged bool) ([]ChangedFile, error) {
-	if r.linkedWorktree {
-		return r.changedFilesShell(staged)
-	}
-	files, err := r.changedFilesGoGit(staged)
-	if err != nil {
-		return r.changedFilesShell(staged)
-	}
-	return files, nil
-}
-
-func (r *Repo) changedFilesGoGit(staged bool) ([]ChangedFile, error) {
-	wt, err := r.repo.Worktree()
-	if err != nil {
-		return nil, err
-	}
-
-	status, err := wt.Status()
+// ChangedFiles lists the worktree's unstaged changes, or with staged set,
+// the index's. With untracked set, unstaged changes include untracked files
+// as Added, with Untracked set; .gitignore'd files are left out.
+func (r *Repo) ChangedFiles(staged, untracked bool) ([]ChangedFile, error) {
+	b, err := r.Backend()
 	if err != nil {
 		return nil, err
 	}
-
-	var files []ChangedFile
-	for path, s := range status {
-		var code string
-		if staged {
-			if s.Staging == '?' || s.Staging == ' ' || s.Staging == 0 {
-				continue
-			}
-			code = statusCodeToString(s.Staging)
-		} else {
-			code = statusCodeToString(s.Worktree)
-		}
-		if code == "" {
-			continue
-		}
-		files = append(files, ChangedFile{Path: path, Status: code})
-	}
-
-	return files, nil
+	return b.ChangedFiles(staged, untracked)
 }
 
-// changedFilesShell falls back to git diff when go-git can't compute
-// status correctly (e.g. in linked worktree layouts).
-func (r *Repo) changedFilesShell(staged bool) ([]ChangedFile, error) {
-	args := []string{"diff"}
-	if staged {
-		args = append(args, "--staged")
-	}
-	args = append(args, "--name-status")
-	cmd := exec.Command("git", args...)
-	cmd.Dir = r.root
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("git diff: %w", err)
-	}
-	return parseNameStatus(string(out)), nil
+// DiffRange is what rift diff compares: Target against Base, or with no
+// Target, the worktree or index against Base.
+type DiffRange struct {
+	Base   string
+	Target string
+	// Symmetric marks an A...B range, which compares Target with its merge
+	// base with Base: the changes made on Target since it branched off.
+	// ResolveDiffRange fills in MergeBase.
+	Symmetric bool
+	MergeBase string
 }
 
-func parseNameStatus(out string) []ChangedFile {
-	var files []ChangedFile
-	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		files = append(files, ChangedFile{
-			Path:   parts[1],
-			Status: nameStatusCode(parts[0]),
-		})
+// From is the commit to diff against: Base, or for A...B the merge base.
+func (d DiffRange) From() string {
+	if d.Symmetric {
+		return d.MergeBase
 	}
-	return files
+	return d.Base
 }
 
-func nameStatusCode(code string) string {
+// String describes the range for a title, with the merge base an A...B
+// range resolved to.
+func (d DiffRange) String() string {
 	switch {
-	case strings.HasPrefix(code, "M"):
-		return "Modified"
-	case strings.HasPrefix(code, "A"):
-		return "Added"
-	case strings.HasPrefix(code, "D"):
-		return "Deleted"
-	case strings.HasPrefix(code, "R"):
-		return "Renamed"
-	case strings.HasPrefix(code, "C"):
-		return "Copied"
+	case d.Target == "":
+		return d.Base
+	case d.Symmetric && len(d.MergeBase) >= 7:
+		return fmt.Sprintf("%s...%s (%s..%s)", d.Base, d.Target, d.MergeBase[:7], d.Target)
+	case d.Symmetric:
+		return d.Base + "..." + d.Target
 	default:
-		return code
+		return d.Base + ".." + d.Target
 	}
 }
 
-func DiffTargets(args []string) (base, target string, err error) {
+// DiffTargets reads rift diff's revision arguments as git diff does: none
+// (HEAD), one commit, two commits, or one A..B or A...B range, where an
+// omitted side means HEAD.
+func DiffTargets(args []string) (DiffRange, error) {
 	switch len(args) {
 	case 0:
-		return "HEAD", "", nil
+		return DiffRange{Base: "HEAD"}, nil
 	case 1:
-		return args[0], "", nil
+		from, to, symmetric, ok := splitRange(args[0])
+		if !ok {
+			return DiffRange{Base: args[0]}, nil
+		}
+		return DiffRange{Base: from, Target: to, Symmetric: symmetric}, nil
 	case 2:
-		return args[0], args[1], nil
+		for _, arg := range args {
+			if _, _, _, ok := splitRange(arg); ok {
+				return DiffRange{}, fmt.Errorf("%q: a range can't be combined with another commit", arg)
+			}
+		}
+		return DiffRange{Base: args[0], Target: args[1]}, nil
 	default:
-		return "", "", fmt.Errorf("too many arguments: expected at most 2 commit refs")
+		return DiffRange{}, fmt.Errorf("too many arguments: expected at most 2 commit refs")
 	}
 }
 
-func (r *Repo) DiffBetweenCommits(baseRef, targetRef string) ([]ChangedFile, error) {
-	baseCommit, err := r.resolveCommit(baseRef)
-	if err != nil {
-		return nil, fmt.Errorf("resolve base %q: %w", baseRef, err)
+// ResolveDiffRange checks that d's commits exist, returning a *RefError if
+// not, and finds an A...B range's merge base.
+func (r *Repo) ResolveDiffRange(d DiffRange) (DiffRange, error) {
+	for _, ref := range []string{d.Base, d.Target} {
+		if ref == "" {
+			continue
+		}
+		if err := r.VerifyRef(ref); err != nil {
+			return DiffRange{}, err
+		}
 	}
-
-	targetCommit, err := r.resolveCommit(targetRef)
-	if err != nil {
-		return nil, fmt.Errorf("resolve target %q: %w", targetRef, err)
+	if d.Symmetric {
+		base, err := r.mergeBase(d.Base, d.Target)
+		if err != nil {
+			return DiffRange{}, err
+		}
+		d.MergeBase = base
 	}
+	return d, nil
+}
 
-	baseTree, err := baseCommit.Tree()
-	if err != nil {
-		return nil, fmt.Errorf("get base tree: %w", err)
+// splitRange splits an A..B or A...B range, filling in HEAD for an omitted
+// side. ok is false if arg isn't a range.
+func splitRange(arg string) (from, to string, symmetric, ok bool) {
+	sep := ".."
+	i := strings.Index(arg, "...")
+	if i >= 0 {
+		sep, symmetric = "...", true
+	} else if i = strings.Index(arg, ".."); i < 0 {
+		return "", "", false, false
 	}
-
-	targetTree, err := targetCommit.Tree()
-	if err != nil {
-		return nil, fmt.Errorf("get target tree: %w", err)
+	from, to = arg[:i], arg[i+len(sep):]
+	if from == "" {
+		from = "HEAD"
 	}
-
-	changes, err := baseTree.Diff(targetTree)
-	if err != nil {
-		return nil, fmt.Errorf("diff trees: %w", err)
+	if to == "" {
+		to = "HEAD"
 	}
+	return from, to, symmetric, true
+}
 
-	var files []ChangedFile
-	for _, c := range changes {
-		name := c.To.Name
-		if name == "" {
-			name = c.From.Name
-		}
-		files = append(files, ChangedFile{
-			Path:   name,
-			Status: diffActionString(c),
-		})
+// mergeBase returns the full hash of the best common ancestor of a and b.
+func (r *Repo) mergeBase(a, b string) (string, error) {
+	backend, err := r.Backend()
+	if err != nil {
+		return "", err
 	}
-
-	return files, nil
+	return backend.MergeBase(a, b)
 }
 
-func (r *Repo) resolveCommit(ref string) (*object.Commit, error) {
-	h, err := r.repo.ResolveRevision(plumbing.Revision(ref))
+// DiffBetweenCommits lists the files that differ between two commits.
+func (r *Repo) DiffBetweenCommits(baseRef, targetRef string) ([]ChangedFile, error) {
+	b, err := r.Backend()
 	if err != nil {
 		return nil, err
 	}
-	return r.repo.CommitObject(*h)
+	return b.DiffCommits(baseRef, targetRef)
 }
 
 func diffActionString(c *object.Change) string {
@@ -179,27 +154,22 @@ func diffActionString(c *object.Change) string {
 		return "Added"
 	case from != "" && to == "":
 		return "Deleted"
+	case from != to:
+		return "Renamed"
 	default:
 		return "Modified"
 	}
 }
 
-func matchPath(file string, paths []string) bool {
-	for _, p := range paths {
-		if file == p || strings.HasPrefix(file, strings.TrimSuffix(p, "/")+"/") {
-			return true
-		}
-	}
-	return false
-}
-
-func FilterByPaths(files []ChangedFile, paths []string) []ChangedFile {
-	if len(paths) == 0 {
+// FilterByPaths keeps the files spec selects by either their path or, for
+// renames and copies, the path they came from.
+func FilterByPaths(files []ChangedFile, spec Pathspec) []ChangedFile {
+	if spec.IsEmpty() {
 		return files
 	}
 	filtered := []ChangedFile{}
 	for _, f := range files {
-		if matchPath(f.Path, paths) {
+		if spec.Match(f.Path) || (f.OldPath != "" && spec.Match(f.OldPath)) {
 			filtered = append(filtered, f)
 		}
 	}
@@ -220,6 +190,8 @@ func statusCodeToString(c gogit.StatusCode) string {
 		return "Copied"
 	case '?':
 		return "Untracked"
+	case 'U':
+		return "Unmerged"
 	case ' ':
 		return ""
 	default:
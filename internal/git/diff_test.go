@@ -1,6 +1,10 @@
 package git
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	gogit "github.com/go-git/go-git/v6"
@@ -9,37 +13,95 @@ import (
 
 func TestDiffTargets(t *testing.T) {
 	tests := []struct {
-		name       string
-		args       []string
-		wantBase   string
-		wantTarget string
-		wantErr    bool
+		name    string
+		args    []string
+		want    DiffRange
+		wantErr bool
 	}{
-		{"zero args defaults to HEAD", []string{}, "HEAD", "", false},
-		{"one arg is base", []string{"main"}, "main", "", false},
-		{"two args are base and target", []string{"abc", "def"}, "abc", "def", false},
-		{"three args is error", []string{"a", "b", "c"}, "", "", true},
+		{"zero args defaults to HEAD", []string{}, DiffRange{Base: "HEAD"}, false},
+		{"one arg is base", []string{"main"}, DiffRange{Base: "main"}, false},
+		{"two args are base and target", []string{"abc", "def"}, DiffRange{Base: "abc", Target: "def"}, false},
+		{"two-dot range", []string{"main..feature"}, DiffRange{Base: "main", Target: "feature"}, false},
+		{"three-dot range", []string{"main...feature"}, DiffRange{Base: "main", Target: "feature", Symmetric: true}, false},
+		{"omitted target is HEAD", []string{"main..."}, DiffRange{Base: "main", Target: "HEAD", Symmetric: true}, false},
+		{"omitted base is HEAD", []string{"..feature"}, DiffRange{Base: "HEAD", Target: "feature"}, false},
+		{"range with another commit is error", []string{"a..b", "c"}, DiffRange{}, true},
+		{"three args is error", []string{"a", "b", "c"}, DiffRange{}, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			base, target, err := DiffTargets(tt.args)
+			got, err := DiffTargets(tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("DiffTargets() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if err != nil {
-				return
-			}
-			if base != tt.wantBase {
-				t.Errorf("base = %q, want %q", base, tt.wantBase)
-			}
-			if target != tt.wantTarget {
-				t.Errorf("target = %q, want %q", target, tt.wantTarget)
+			if got != tt.want {
+				t.Errorf("DiffTargets() = %+v, want %+v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestResolveDiffRange(t *testing.T) {
+	repo := setupTestRepo(t)
+	wt, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	head, err := repo.repo.Head()
+	if err != nil {
+		t.Fatalf("get head: %v", err)
+	}
+	fork := head.Hash()
+
+	// feature adds feature.txt; main moves on with main.txt.
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: "refs/heads/feature", Create: true}); err != nil {
+		t.Fatalf("checkout feature: %v", err)
+	}
+	writeFile(t, repo.root, "feature.txt", "feature\n")
+	if _, err := wt.Add("feature.txt"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	testCommit(t, wt, "feature work")
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: "refs/heads/master"}); err != nil {
+		t.Fatalf("checkout master: %v", err)
+	}
+	writeFile(t, repo.root, "main.txt", "main\n")
+	if _, err := wt.Add("main.txt"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	testCommit(t, wt, "main work")
+
+	rng, err := DiffTargets([]string{"master...feature"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng, err = repo.ResolveDiffRange(rng)
+	if err != nil {
+		t.Fatalf("ResolveDiffRange() error = %v", err)
+	}
+	if rng.From() != fork.String() {
+		t.Errorf("From() = %s, want the fork point %s", rng.From(), fork)
+	}
+	if want := "master...feature (" + fork.String()[:7] + "..feature)"; rng.String() != want {
+		t.Errorf("String() = %q, want %q", rng.String(), want)
+	}
+
+	files, err := repo.DiffBetweenCommits(rng.From(), rng.Target)
+	if err != nil {
+		t.Fatalf("DiffBetweenCommits() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "feature.txt" {
+		t.Errorf("master...feature changed %+v, want only feature.txt", files)
+	}
+
+	if _, err := repo.ResolveDiffRange(DiffRange{Base: "master", Target: "nope"}); err == nil {
+		t.Error("ResolveDiffRange() with an unknown commit succeeded")
+	} else if refErr := (*RefError)(nil); !errors.As(err, &refErr) || refErr.Ref != "nope" {
+		t.Errorf("ResolveDiffRange() error = %v, want a RefError for nope", err)
+	}
+}
+
 func TestStatusCodeToString(t *testing.T) {
 	tests := []struct {
 		name string
@@ -76,6 +138,7 @@ func TestDiffActionString(t *testing.T) {
 		{"added", "", "newfile.go", "Added"},
 		{"deleted", "oldfile.go", "", "Deleted"},
 		{"modified", "file.go", "file.go", "Modified"},
+		{"renamed", "old.go", "new.go", "Renamed"},
 	}
 
 	for _, tt := range tests {
@@ -117,7 +180,7 @@ func TestChangedFiles_Unstaged(t *testing.T) {
 
 	writeFile(t, repo.root, "README.md", "# modified\n")
 
-	files, err := repo.ChangedFiles(false)
+	files, err := repo.ChangedFiles(false, false)
 	if err != nil {
 		t.Fatalf("ChangedFiles() error = %v", err)
 	}
@@ -129,6 +192,39 @@ func TestChangedFiles_Unstaged(t *testing.T) {
 	}
 }
 
+func TestChangedFiles_Untracked(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	writeFile(t, repo.root, "README.md", "# modified\n")
+	writeFile(t, repo.root, "new/file.go", "package new\n")
+	writeFile(t, repo.root, "build.log", "noise\n")
+	writeFile(t, repo.root, ".gitignore", "*.log\n")
+
+	for name, b := range backends(repo) {
+		files, err := b.ChangedFiles(false, true)
+		if err != nil {
+			t.Fatalf("%s: ChangedFiles() error = %v", name, err)
+		}
+		slices.SortFunc(files, func(a, b ChangedFile) int { return strings.Compare(a.Path, b.Path) })
+		want := []ChangedFile{
+			{Path: ".gitignore", Status: "Added", Untracked: true},
+			{Path: "README.md", Status: "Modified"},
+			{Path: "new/file.go", Status: "Added", Untracked: true},
+		}
+		if !slices.Equal(files, want) {
+			t.Errorf("%s: ChangedFiles(untracked) = %+v, want %+v", name, files, want)
+		}
+
+		files, err = b.ChangedFiles(false, false)
+		if err != nil {
+			t.Fatalf("%s: ChangedFiles() error = %v", name, err)
+		}
+		if len(files) != 1 || files[0].Path != "README.md" {
+			t.Errorf("%s: ChangedFiles(no untracked) = %+v, want only README.md", name, files)
+		}
+	}
+}
+
 func TestChangedFiles_Staged(t *testing.T) {
 	repo := setupTestRepo(t)
 
@@ -141,7 +237,7 @@ func TestChangedFiles_Staged(t *testing.T) {
 		t.Fatalf("git add: %v", err)
 	}
 
-	files, err := repo.ChangedFiles(true)
+	files, err := repo.ChangedFiles(true, false)
 	if err != nil {
 		t.Fatalf("ChangedFiles() error = %v", err)
 	}
@@ -196,3 +292,151 @@ func TestDiffBetweenCommits(t *testing.T) {
 		t.Errorf("README.md status = %q, want %q", byPath["README.md"], "Modified")
 	}
 }
+
+func TestParseStatusV2(t *testing.T) {
+	out := strings.Join([]string{
+		"# branch.oid 1234",
+		"1 .M N... 100644 100644 100644 aaa aaa main.go",
+		"1 A. N... 000000 100644 100644 000 bbb with space.go",
+		"2 R. N... 100644 100644 100644 ccc ccc R086 new.go", "old.go",
+		"2 CM N... 100644 100644 100644 ddd ddd C100 dst.go", "src.go",
+		"u UU N... 100644 100644 100644 100644 e1 e2 e3 conflict.go",
+		"1 .T N... 100644 120000 120000 fff fff link",
+		"? line\nbreak.txt",
+		"! ignored.log",
+	}, "\x00") + "\x00"
+
+	got, err := parseStatusV2([]byte(out))
+	if err != nil {
+		t.Fatalf("parseStatusV2() error = %v", err)
+	}
+	want := []statusEntry{
+		{Path: "main.go", Worktree: "Modified"},
+		{Path: "with space.go", Staging: "Added"},
+		{Path: "new.go", OldPath: "old.go", Similarity: 86, Staging: "Renamed"},
+		{Path: "dst.go", OldPath: "src.go", Similarity: 100, Staging: "Copied", Worktree: "Modified"},
+		{Path: "conflict.go", Staging: "Unmerged", Worktree: "Unmerged"},
+		{Path: "link", Worktree: "Modified"},
+		{Path: "line\nbreak.txt", Staging: "Untracked", Worktree: "Untracked"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseStatusV2() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	for _, bad := range []string{"1 .M N... 100644 main.go\x00", "2 R. N... 100644 100644 100644 c c R100 new.go\x00", "x what\x00"} {
+		if _, err := parseStatusV2([]byte(bad)); err == nil {
+			t.Errorf("parseStatusV2(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestDiffBetweenCommits_Rename(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	wt, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+
+	content := "package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n"
+	writeFile(t, repo.root, "old.go", content)
+	if _, err := wt.Add("old.go"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	baseHash := testCommit(t, wt, "add old.go")
+
+	if _, err := wt.Remove("old.go"); err != nil {
+		t.Fatalf("git rm: %v", err)
+	}
+	writeFile(t, repo.root, "new.go", content+"\nfunc d() {}\n")
+	if _, err := wt.Add("new.go"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	targetHash := testCommit(t, wt, "rename to new.go")
+
+	files, err := repo.DiffBetweenCommits(baseHash.String(), targetHash.String())
+	if err != nil {
+		t.Fatalf("DiffBetweenCommits() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 renamed file, got %d: %v", len(files), files)
+	}
+	f := files[0]
+	if f.Path != "new.go" || f.OldPath != "old.go" || f.Status != "Renamed" {
+		t.Errorf("got %+v, want old.go renamed to new.go", f)
+	}
+	if f.Similarity < renameThreshold || f.Similarity >= 100 {
+		t.Errorf("Similarity = %d, want in [%d, 100)", f.Similarity, renameThreshold)
+	}
+}
+
+func TestChangedFiles_StagedRename(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	wt, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	if _, err := wt.Move("README.md", "docs/README.md"); err != nil {
+		t.Fatalf("git mv: %v", err)
+	}
+
+	files, err := repo.ChangedFiles(true, false)
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 staged file, got %d: %v", len(files), files)
+	}
+	want := ChangedFile{Path: "docs/README.md", OldPath: "README.md", Status: "Renamed", Similarity: 100}
+	if files[0] != want {
+		t.Errorf("got %+v, want %+v", files[0], want)
+	}
+}
+
+func TestDetectRenames_Copy(t *testing.T) {
+	old := map[string]string{"a.txt": "one\ntwo\nthree\n"}
+	new := map[string]string{"a.txt": "one\ntwo\nthree\nfour\n", "b.txt": "one\ntwo\nthree\n"}
+	lookup := func(m map[string]string) contentFunc {
+		return func(path string) ([]byte, error) {
+			s, ok := m[path]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", path)
+			}
+			return []byte(s), nil
+		}
+	}
+
+	files := []ChangedFile{
+		{Path: "a.txt", Status: "Modified"},
+		{Path: "b.txt", Status: "Added"},
+	}
+	got := detectRenames(files, lookup(old), lookup(new))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 files, got %d: %v", len(got), got)
+	}
+	want := ChangedFile{Path: "b.txt", OldPath: "a.txt", Status: "Copied", Similarity: 100}
+	if got[1] != want {
+		t.Errorf("got %+v, want %+v", got[1], want)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{"identical", "a\nb\n", "a\nb\n", 100},
+		{"disjoint", "a\nb\n", "c\nd\n", 0},
+		{"empty side", "", "a\n", 0},
+		{"half shared", "a\nb\n", "a\nc\n", 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarity([]byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("similarity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// discardTimeFormat prefixes every saved patch so IDs sort chronologically.
+const discardTimeFormat = "20060102-150405.000"
+
+// DiscardedPatch is a worktree change thrown away by rift and saved under
+// .git/rift/discarded/ so it can be restored.
+type DiscardedPatch struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Date string `json:"date"`
+}
+
+// DiscardHunk reverse-applies a single hunk patch to the worktree after
+// saving it for RestoreDiscarded.
+func (r *Repo) DiscardHunk(path, patch string) error {
+	saved, err := r.saveDiscarded(path, patch)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "-C", r.root, "apply", "--reverse", "--unidiff-zero", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(saved)
+		return fmt.Errorf("git apply --reverse: %s: %w", out, err)
+	}
+	return nil
+}
+
+// DiscardFile throws away the unstaged changes to path, deleting it if it is
+// untracked. The discarded changes are saved for RestoreDiscarded first.
+func (r *Repo) DiscardFile(path string) error {
+	tracked := exec.Command("git", "-C", r.root, "ls-files", "--error-unmatch", "--", path).Run() == nil
+
+	var args []string
+	if tracked {
+		args = []string{"-C", r.root, "diff", "--no-color", "--binary", "--", path}
+	} else {
+		args = []string{"-C", r.root, "diff", "--no-color", "--binary", "--no-index", "--", "/dev/null", path}
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		// git diff exits 1 when there are differences — that's not an error
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return fmt.Errorf("git diff: %w", err)
+		}
+	}
+	if len(out) == 0 {
+		return fmt.Errorf("no unstaged changes to discard in %s", path)
+	}
+
+	saved, err := r.saveDiscarded(path, string(out))
+	if err != nil {
+		return err
+	}
+	if !tracked {
+		if err := os.Remove(filepath.Join(r.root, path)); err != nil {
+			os.Remove(saved)
+			return fmt.Errorf("remove %s: %w", path, err)
+		}
+		return nil
+	}
+	cmd := exec.Command("git", "-C", r.root, "checkout", "--", path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(saved)
+		return fmt.Errorf("git checkout: %s: %w", out, err)
+	}
+	return nil
+}
+
+// ListDiscarded returns saved discards, newest first.
+func (r *Repo) ListDiscarded() ([]DiscardedPatch, error) {
+	dir, err := r.discardDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []DiscardedPatch{}, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	patches := []DiscardedPatch{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".patch")
+		if !ok {
+			continue
+		}
+		if p, ok := parseDiscardID(id); ok {
+			patches = append(patches, p)
+		}
+	}
+	sort.Slice(patches, func(i, j int) bool {
+		return patches[i].ID > patches[j].ID
+	})
+	return patches, nil
+}
+
+// RestoreDiscarded re-applies a saved discard to the worktree and deletes it.
+func (r *Repo) RestoreDiscarded(id string) error {
+	dir, err := r.discardDir()
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, id+".patch")
+	patch, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read discarded patch %s: %w", id, err)
+	}
+	cmd := exec.Command("git", "-C", r.root, "apply", "--unidiff-zero", "-")
+	cmd.Stdin = bytes.NewReader(patch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply: %s: %w", out, err)
+	}
+	return os.Remove(file)
+}
+
+func (r *Repo) saveDiscarded(path, patch string) (string, error) {
+	dir, err := r.discardDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create %s: %w", dir, err)
+	}
+	id := time.Now().Format(discardTimeFormat) + "-" + url.PathEscape(path)
+	file := filepath.Join(dir, id+".patch")
+	if err := os.WriteFile(file, []byte(patch), 0600); err != nil {
+		return "", fmt.Errorf("save discarded patch: %w", err)
+	}
+	return file, nil
+}
+
+// discardDir resolves .git/rift/discarded for this worktree. In linked
+// worktrees the git dir lives outside the worktree, so ask git for it.
+func (r *Repo) discardDir() (string, error) {
+	out, err := exec.Command("git", "-C", r.root, "rev-parse", "--git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --git-dir: %w", err)
+	}
+	gitDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(r.root, gitDir)
+	}
+	return filepath.Join(gitDir, "rift", "discarded"), nil
+}
+
+// parseDiscardID splits "20250115-103000.000-cmd%2Fdiff.go" into its date
+// and path.
+func parseDiscardID(id string) (DiscardedPatch, bool) {
+	n := len(discardTimeFormat)
+	if len(id) < n+2 || id[n] != '-' {
+		return DiscardedPatch{}, false
+	}
+	t, err := time.ParseInLocation(discardTimeFormat, id[:n], time.Local)
+	if err != nil {
+		return DiscardedPatch{}, false
+	}
+	path, err := url.PathUnescape(id[n+1:])
+	if err != nil {
+		return DiscardedPatch{}, false
+	}
+	return DiscardedPatch{ID: id, Path: path, Date: t.Format("2006-01-02 15:04")}, true
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(b)
+}
+
+func TestDiscardFile_RestoreRoundTrip(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	writeFile(t, repo.root, "README.md", "# changed\n")
+	if err := repo.DiscardFile("README.md"); err != nil {
+		t.Fatalf("DiscardFile() error = %v", err)
+	}
+	if got := readFile(t, repo.root, "README.md"); got != "# test repo\n" {
+		t.Errorf("after discard README.md = %q, want original content", got)
+	}
+
+	patches, err := repo.ListDiscarded()
+	if err != nil {
+		t.Fatalf("ListDiscarded() error = %v", err)
+	}
+	if len(patches) != 1 || patches[0].Path != "README.md" {
+		t.Fatalf("ListDiscarded() = %v, want one entry for README.md", patches)
+	}
+
+	if err := repo.RestoreDiscarded(patches[0].ID); err != nil {
+		t.Fatalf("RestoreDiscarded() error = %v", err)
+	}
+	if got := readFile(t, repo.root, "README.md"); got != "# changed\n" {
+		t.Errorf("after restore README.md = %q, want %q", got, "# changed\n")
+	}
+	if patches, _ := repo.ListDiscarded(); len(patches) != 0 {
+		t.Errorf("expected restored patch to be removed, got %v", patches)
+	}
+}
+
+func TestDiscardFile_Untracked(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	writeFile(t, repo.root, "dir/new.txt", "scratch\n")
+	if err := repo.DiscardFile("dir/new.txt"); err != nil {
+		t.Fatalf("DiscardFile() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo.root, "dir/new.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected untracked file to be removed, stat err = %v", err)
+	}
+
+	patches, err := repo.ListDiscarded()
+	if err != nil {
+		t.Fatalf("ListDiscarded() error = %v", err)
+	}
+	if len(patches) != 1 || patches[0].Path != "dir/new.txt" {
+		t.Fatalf("ListDiscarded() = %v, want one entry for dir/new.txt", patches)
+	}
+	if err := repo.RestoreDiscarded(patches[0].ID); err != nil {
+		t.Fatalf("RestoreDiscarded() error = %v", err)
+	}
+	if got := readFile(t, repo.root, "dir/new.txt"); got != "scratch\n" {
+		t.Errorf("after restore new.txt = %q, want %q", got, "scratch\n")
+	}
+}
+
+func TestDiscardFile_NoChanges(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	if err := repo.DiscardFile("README.md"); err == nil {
+		t.Fatal("expected error discarding an unchanged file")
+	}
+	if patches, _ := repo.ListDiscarded(); len(patches) != 0 {
+		t.Errorf("expected nothing saved, got %v", patches)
+	}
+}
+
+func TestParseDiscardID(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantPath string
+		wantOK   bool
+	}{
+		{"nested path", "20250115-103000.123-cmd%2Fdiff.go", "cmd/diff.go", true},
+		{"plain path", "20250115-103000.123-main.go", "main.go", true},
+		{"missing path", "20250115-103000.123", "", false},
+		{"bad timestamp", "not-a-timestamp-at-all-main.go", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseDiscardID(tt.id)
+			if ok != tt.wantOK {
+				t.Fatalf("parseDiscardID(%q) ok = %v, want %v", tt.id, ok, tt.wantOK)
+			}
+			if ok && got.Path != tt.wantPath {
+				t.Errorf("Path = %q, want %q", got.Path, tt.wantPath)
+			}
+		})
+	}
+}
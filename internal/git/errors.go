@@ -0,0 +1,34 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// ErrNotRepository is returned by OpenRepo outside a git repository.
+var ErrNotRepository = errors.New("not a git repository (or any parent up to /)")
+
+// RefError reports a revision that doesn't name a commit.
+type RefError struct {
+	Ref string
+	Err error
+}
+
+func (e *RefError) Error() string {
+	return fmt.Sprintf("unknown revision %q", e.Ref)
+}
+
+func (e *RefError) Unwrap() error { return e.Err }
+
+// VerifyRef checks that ref names a commit, returning a *RefError if not.
+func (r *Repo) VerifyRef(ref string) error {
+	return verifyRef(r.root, ref)
+}
+
+func verifyRef(root, ref string) error {
+	if err := exec.Command("git", "-C", root, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run(); err != nil {
+		return &RefError{Ref: ref, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package git
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOpenRepo_NotARepository(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if _, err := OpenRepo(); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("OpenRepo() outside a repo error = %v, want ErrNotRepository", err)
+	}
+}
+
+func TestRefErrors(t *testing.T) {
+	repo := setupTestRepo(t)
+	t.Chdir(repo.root)
+
+	if err := repo.VerifyRef("HEAD"); err != nil {
+		t.Errorf("VerifyRef(HEAD) error = %v", err)
+	}
+
+	checks := map[string]func() error{
+		"VerifyRef": func() error { return repo.VerifyRef("nope") },
+		"Log": func() error {
+			_, err := repo.Log("nope", 0, Pathspec{})
+			return err
+		},
+		"MergeBase": func() error {
+			_, err := repo.MergeBase("nope")
+			return err
+		},
+		"DiffBetweenCommits": func() error {
+			_, err := repo.DiffBetweenCommits("HEAD", "nope")
+			return err
+		},
+	}
+	for name, check := range checks {
+		var refErr *RefError
+		if err := check(); !errors.As(err, &refErr) || refErr.Ref != "nope" {
+			t.Errorf("%s() error = %v, want a *RefError for %q", name, err, "nope")
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Upstream returns the upstream of the current branch, e.g. "origin/main".
+func (r *Repo) Upstream() (string, error) {
+	out, err := exec.Command("git", "-C", r.root, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		return "", fmt.Errorf("no upstream configured for the current branch")
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// MergeBase returns the full hash of the best common ancestor of HEAD and ref.
+func (r *Repo) MergeBase(ref string) (string, error) {
+	if err := r.VerifyRef(ref); err != nil {
+		return "", err
+	}
+	out, err := exec.Command("git", "-C", r.root, "merge-base", "HEAD", ref).Output()
+	if err != nil {
+		return "", fmt.Errorf("git merge-base HEAD %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CommitsSince lists the commits reachable from HEAD but not from base,
+// newest first.
+func (r *Repo) CommitsSince(base string) ([]CommitInfo, error) {
+	return r.logRange(base + "..HEAD")
+}
+
+// logRange runs git log with extra args (a revision range and any filters)
+// and parses the result.
+func (r *Repo) logRange(args ...string) ([]CommitInfo, error) {
+	cmdArgs := append([]string{"-C", r.root, "log", logFormat}, args...)
+	out, err := exec.Command("git", cmdArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log %s: %w", strings.Join(args, " "), err)
+	}
+	commits := parseGitLogOutput(string(out))
+	if commits == nil {
+		commits = []CommitInfo{}
+	}
+	return commits, nil
+}
+
+// RevList returns the full hashes of the commits reachable from HEAD but not
+// from base, newest first.
+func (r *Repo) RevList(base string) ([]string, error) {
+	out, err := exec.Command("git", "-C", r.root, "rev-list", base+"..HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list %s..HEAD: %w", base, err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// Fixup commits the index as a "fixup!" of target, running hooks.
+func (r *Repo) Fixup(target string) (CommitInfo, error) {
+	cmd := exec.Command("git", "-C", r.root, "commit", "--fixup="+target)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return CommitInfo{}, fmt.Errorf("git commit --fixup: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return r.headCommit()
+}
+
+// FixupPatch commits patch, applied to HEAD, as a "fixup!" of target,
+// running hooks. The patch is staged in an index of its own, seeded from
+// HEAD, so the repository's index is left alone.
+func (r *Repo) FixupPatch(target, patch string) (CommitInfo, error) {
+	dir, err := os.MkdirTemp("", "rift-fixup-")
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("create temporary index: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(dir, "index"))
+
+	for _, args := range [][]string{
+		{"read-tree", "HEAD"},
+		{"apply", "--cached", "--unidiff-zero", "-"},
+		{"commit", "--fixup=" + target},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", r.root}, args...)...)
+		cmd.Env = env
+		cmd.Stdin = strings.NewReader(patch)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return CommitInfo{}, fmt.Errorf("git %s: %s: %w", strings.Join(args[:2], " "), strings.TrimSpace(string(out)), err)
+		}
+	}
+	return r.headCommit()
+}
+
+// Autosquash folds fixup! and squash! commits on top of base into their
+// targets with a non-interactive rebase. Local changes to tracked files are
+// stashed for the duration and restored with their staged state intact. On
+// conflict the rebase is aborted, leaving HEAD as it was.
+func (r *Repo) Autosquash(base string) error {
+	stashed, err := r.stashTracked()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "-C", r.root, "rebase", "--interactive", "--autosquash", base)
+	// Accept the generated todo list and any squash messages unchanged.
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true")
+	out, rebaseErr := cmd.CombinedOutput()
+	if rebaseErr != nil && r.RebaseInProgress() {
+		if abortOut, err := exec.Command("git", "-C", r.root, "rebase", "--abort").CombinedOutput(); err != nil {
+			return fmt.Errorf("git rebase --abort: %s: %w", strings.TrimSpace(string(abortOut)), err)
+		}
+		rebaseErr = fmt.Errorf("autosquash rebase stopped on a conflict and was aborted: %s", strings.TrimSpace(string(out)))
+	} else if rebaseErr != nil {
+		rebaseErr = fmt.Errorf("git rebase --autosquash: %s: %w", strings.TrimSpace(string(out)), rebaseErr)
+	}
+
+	if stashed {
+		if out, err := exec.Command("git", "-C", r.root, "stash", "pop", "-q", "--index").CombinedOutput(); err != nil {
+			return fmt.Errorf("git stash pop: %s: %w (your changes are still in the stash)", strings.TrimSpace(string(out)), err)
+		}
+	}
+	return rebaseErr
+}
+
+// stashTracked stashes staged and unstaged changes to tracked files, if
+// there are any, and reports whether it did.
+func (r *Repo) stashTracked() (bool, error) {
+	dirty, err := r.HasTrackedChanges()
+	if err != nil || !dirty {
+		return false, err
+	}
+	if out, err := exec.Command("git", "-C", r.root, "stash", "push", "-q", "-m", "rift autosquash").CombinedOutput(); err != nil {
+		return false, fmt.Errorf("git stash push: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return true, nil
+}
+
+// HasTrackedChanges reports whether tracked files have staged or unstaged
+// changes. Untracked files don't count.
+func (r *Repo) HasTrackedChanges() (bool, error) {
+	out, err := exec.Command("git", "-C", r.root, "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return false, fmt.Errorf("git status: %w", err)
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+// RebaseInProgress reports whether a rebase has stopped partway through,
+// e.g. at an edit step or on a conflict.
+func (r *Repo) RebaseInProgress() bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		out, err := exec.Command("git", "-C", r.root, "rev-parse", "--git-path", dir).Output()
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(string(out))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.root, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
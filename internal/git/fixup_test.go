@@ -0,0 +1,129 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+// commitFile stages content at name and commits it through the git CLI.
+func commitFile(t *testing.T, repo *Repo, name, content, msg string) CommitInfo {
+	t.Helper()
+	stageFile(t, repo, name, content)
+	info, err := repo.Commit(msg, false)
+	if err != nil {
+		t.Fatalf("Commit(%q) error = %v", msg, err)
+	}
+	return info
+}
+
+func TestCommitsSince(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	base, err := repo.MergeBase("HEAD")
+	if err != nil {
+		t.Fatalf("MergeBase() error = %v", err)
+	}
+	commitFile(t, repo, "a.txt", "a\n", "Add a")
+	commitFile(t, repo, "b.txt", "b\n", "Add b")
+
+	commits, err := repo.CommitsSince(base)
+	if err != nil {
+		t.Fatalf("CommitsSince() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Message != "Add b" || commits[1].Message != "Add a" {
+		t.Errorf("CommitsSince() = %+v, want [Add b, Add a]", commits)
+	}
+}
+
+func TestFixup_Autosquash(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	base, _ := repo.MergeBase("HEAD")
+	target := commitFile(t, repo, "a.txt", "a\n", "Add a")
+	commitFile(t, repo, "b.txt", "b\n", "Add b")
+
+	stageFile(t, repo, "a.txt", "a\nmore\n")
+	info, err := repo.Fixup(target.Hash)
+	if err != nil {
+		t.Fatalf("Fixup() error = %v", err)
+	}
+	if info.Message != "fixup! Add a" {
+		t.Errorf("Fixup() message = %q, want %q", info.Message, "fixup! Add a")
+	}
+
+	if err := repo.Autosquash(base); err != nil {
+		t.Fatalf("Autosquash() error = %v", err)
+	}
+	commits, err := repo.CommitsSince(base)
+	if err != nil {
+		t.Fatalf("CommitsSince() error = %v", err)
+	}
+	if len(commits) != 2 || commits[1].Message != "Add a" {
+		t.Fatalf("after autosquash commits = %+v, want [Add b, Add a]", commits)
+	}
+	if got := readFile(t, repo.root, "a.txt"); got != "a\nmore\n" {
+		t.Errorf("a.txt = %q, want the fixup folded in", got)
+	}
+}
+
+func TestAutosquash_ConflictAborts(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	base, _ := repo.MergeBase("HEAD")
+	target := commitFile(t, repo, "a.txt", "one\n", "Add a")
+	commitFile(t, repo, "a.txt", "two\n", "Change a")
+
+	// Applying this on top of "Add a" conflicts with "Change a".
+	stageFile(t, repo, "a.txt", "three\n")
+	fixup, err := repo.Fixup(target.Hash)
+	if err != nil {
+		t.Fatalf("Fixup() error = %v", err)
+	}
+
+	err = repo.Autosquash(base)
+	if err == nil || !strings.Contains(err.Error(), "aborted") {
+		t.Fatalf("Autosquash() error = %v, want an aborted conflict", err)
+	}
+	if repo.RebaseInProgress() {
+		t.Error("rebase still in progress after abort")
+	}
+	head, err := repo.headCommit()
+	if err != nil {
+		t.Fatalf("headCommit() error = %v", err)
+	}
+	if head.Hash != fixup.Hash {
+		t.Errorf("HEAD = %s, want the fixup commit %s left in place", head.Hash, fixup.Hash)
+	}
+}
+
+func TestAutosquash_KeepsStagedChanges(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	base, _ := repo.MergeBase("HEAD")
+	target := commitFile(t, repo, "a.txt", "a\n", "Add a")
+	stageFile(t, repo, "a.txt", "a\nmore\n")
+	if _, err := repo.Fixup(target.Hash); err != nil {
+		t.Fatalf("Fixup() error = %v", err)
+	}
+
+	stageFile(t, repo, "README.md", "# staged\n")
+	writeFile(t, repo.root, "a.txt", "a\nmore\nunstaged\n")
+	if err := repo.Autosquash(base); err != nil {
+		t.Fatalf("Autosquash() error = %v", err)
+	}
+
+	staged, err := repo.ChangedFiles(true, false)
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	if len(staged) != 1 || staged[0].Path != "README.md" {
+		t.Errorf("staged after autosquash = %+v, want only README.md", staged)
+	}
+	if got := readFile(t, repo.root, "a.txt"); got != "a\nmore\nunstaged\n" {
+		t.Errorf("a.txt = %q, want unstaged edit restored", got)
+	}
+}
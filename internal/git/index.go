@@ -0,0 +1,34 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// HeadHash returns the full hash of HEAD.
+func (r *Repo) HeadHash() (string, error) {
+	out, err := exec.Command("git", "-C", r.root, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ResetSoft moves HEAD to rev without touching the index or worktree.
+func (r *Repo) ResetSoft(rev string) error {
+	if out, err := exec.Command("git", "-C", r.root, "reset", "--soft", "-q", rev).CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset --soft: %s: %w", out, err)
+	}
+	return nil
+}
+
+// StagedPatch returns the diff of the index against HEAD without context
+// lines, so every hunk is a single contiguous change.
+func (r *Repo) StagedPatch() (string, error) {
+	out, err := exec.Command("git", "-C", r.root, "diff", "--cached", "--no-color", "--no-ext-diff", "-U0").Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff --cached: %w", err)
+	}
+	return string(out), nil
+}
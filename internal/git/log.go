@@ -1,15 +1,11 @@
 package git
 
 import (
-	"fmt"
-	"os/exec"
-	"strconv"
+	"bytes"
+	"iter"
 	"strings"
 
-	gogit "github.com/go-git/go-git/v6"
-	"github.com/go-git/go-git/v6/plumbing"
 	"github.com/go-git/go-git/v6/plumbing/object"
-	"github.com/go-git/go-git/v6/plumbing/storer"
 )
 
 type CommitInfo struct {
@@ -20,151 +16,93 @@ type CommitInfo struct {
 	Body    string `json:"body,omitempty"`
 }
 
-func (r *Repo) Log(ref string, maxCount int, paths []string) ([]CommitInfo, error) {
-	h, err := r.repo.ResolveRevision(plumbing.Revision(ref))
-	if err != nil {
-		return logShell(ref, maxCount, false, paths)
-	}
-
-	commits, err := r.logGoGit(*h, maxCount, paths)
-	if err != nil {
-		return logShell(ref, maxCount, false, paths)
-	}
-	return commits, nil
+// Log returns the commits reachable from ref, newest first; see LogSeq.
+func (r *Repo) Log(ref string, maxCount int, spec Pathspec) ([]CommitInfo, error) {
+	return collectCommits(r.LogSeq(ref, maxCount, spec))
 }
 
-func (r *Repo) LogAll(maxCount int, paths []string) ([]CommitInfo, error) {
-	commits, err := r.logAllGoGit(maxCount, paths)
-	if err != nil {
-		return logShell("", maxCount, true, paths)
-	}
-	return commits, nil
+// LogAll returns the commits reachable from any branch; see LogAllSeq.
+func (r *Repo) LogAll(maxCount int, spec Pathspec) ([]CommitInfo, error) {
+	return collectCommits(r.LogAllSeq(maxCount, spec))
 }
 
-func (r *Repo) logGoGit(from plumbing.Hash, maxCount int, paths []string) ([]CommitInfo, error) {
-	opts := &gogit.LogOptions{
-		From:  from,
-		Order: gogit.LogOrderCommitterTime,
-	}
-	if len(paths) > 0 {
-		opts.PathFilter = func(file string) bool { return matchPath(file, paths) }
-	}
-
-	iter, err := r.repo.Log(opts)
+// LogSeq streams the commits reachable from ref, newest first, stopping after
+// maxCount (0 for no limit). Commits are read as the loop asks for them, so
+// breaking out early stops the walk. An error ends the sequence.
+func (r *Repo) LogSeq(ref string, maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	b, err := r.Backend()
 	if err != nil {
-		return nil, err
+		return failedLog(err)
 	}
-	defer iter.Close()
+	return b.Log(ref, maxCount, spec)
+}
 
-	commits := []CommitInfo{}
-	err = iter.ForEach(func(c *object.Commit) error {
-		if maxCount > 0 && len(commits) >= maxCount {
-			return storer.ErrStop
-		}
-		commits = append(commits, commitToInfo(c))
-		return nil
-	})
+// LogAllSeq streams the commits reachable from any local or remote branch,
+// each once, like LogSeq.
+func (r *Repo) LogAllSeq(maxCount int, spec Pathspec) iter.Seq2[CommitInfo, error] {
+	b, err := r.Backend()
 	if err != nil {
-		return nil, err
+		return failedLog(err)
 	}
-	return commits, nil
+	return b.LogAll(maxCount, spec)
 }
 
-func (r *Repo) logAllGoGit(maxCount int, paths []string) ([]CommitInfo, error) {
-	refs, err := r.repo.References()
-	if err != nil {
-		return nil, err
-	}
+// failedLog is a log that ends at once with err.
+func failedLog(err error) iter.Seq2[CommitInfo, error] {
+	return func(yield func(CommitInfo, error) bool) { yield(CommitInfo{}, err) }
+}
 
-	seen := map[plumbing.Hash]bool{}
+func collectCommits(seq iter.Seq2[CommitInfo, error]) ([]CommitInfo, error) {
 	commits := []CommitInfo{}
-
-	err = refs.ForEach(func(ref *plumbing.Reference) error {
-		if !ref.Name().IsBranch() && !ref.Name().IsRemote() {
-			return nil
-		}
-		opts := &gogit.LogOptions{
-			From:  ref.Hash(),
-			Order: gogit.LogOrderCommitterTime,
-		}
-		if len(paths) > 0 {
-			opts.PathFilter = func(file string) bool { return matchPath(file, paths) }
-		}
-		iter, err := r.repo.Log(opts)
+	for c, err := range seq {
 		if err != nil {
-			return nil
+			return nil, err
 		}
-		defer iter.Close()
-
-		return iter.ForEach(func(c *object.Commit) error {
-			if maxCount > 0 && len(commits) >= maxCount {
-				return storer.ErrStop
-			}
-			if seen[c.Hash] {
-				return nil
-			}
-			seen[c.Hash] = true
-			commits = append(commits, commitToInfo(c))
-			return nil
-		})
-	})
-	if err != nil {
-		return nil, err
+		commits = append(commits, c)
 	}
 	return commits, nil
 }
 
-// logShell falls back to shelling out to git log when go-git can't handle
-// the repo layout (e.g. bare-repo worktree setups).
-func logShell(ref string, maxCount int, all bool, paths []string) ([]CommitInfo, error) {
-	const fieldSep = "\x1e"
-	const recordSep = "\x00"
-	// Use git's %xNN escapes so no special bytes appear in the argument itself.
-	args := []string{"log", "--format=%h%x1e%an%x1e%ai%x1e%s%x1e%b%x00"}
-	if maxCount > 0 {
-		args = append(args, "-n", strconv.Itoa(maxCount))
-	}
-	if all {
-		args = append(args, "--all")
-	} else if ref != "" {
-		args = append(args, ref)
-	}
-	if len(paths) > 0 {
-		args = append(args, "--")
-		args = append(args, paths...)
+// logFormat has git log write commits as parseGitLogRecord reads them:
+// hash, author, date and raw message, separated by RS and ended by NUL.
+// git's %xNN escapes keep special bytes out of the argument itself.
+const logFormat = "--format=%H%x1e%an%x1e%ai%x1e%B%x00"
+
+// scanNULRecords is a bufio.SplitFunc for NUL-terminated records.
+func scanNULRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
 	}
-	out, err := exec.Command("git", args...).Output()
-	if err != nil {
-		return nil, fmt.Errorf("git log: %w", err)
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
 	}
-	return parseGitLogOutput(string(out), fieldSep, recordSep), nil
+	return 0, nil, nil
 }
 
-func parseGitLogOutput(out, fieldSep, recordSep string) []CommitInfo {
+func parseGitLogOutput(out string) []CommitInfo {
 	var commits []CommitInfo
-	for _, record := range strings.Split(out, recordSep) {
-		record = strings.TrimSpace(record)
-		if record == "" {
-			continue
+	for _, record := range strings.Split(out, "\x00") {
+		if ci, ok := parseGitLogRecord(record); ok {
+			commits = append(commits, ci)
 		}
-		parts := strings.SplitN(record, fieldSep, 5)
-		if len(parts) < 4 {
-			continue
-		}
-		ci := CommitInfo{
-			Hash:    parts[0],
-			Author:  parts[1],
-			Date:    formatShellDate(parts[2]),
-			Message: parts[3],
-		}
-		if len(parts) == 5 {
-			ci.Body = strings.TrimSpace(parts[4])
-		}
-		commits = append(commits, ci)
 	}
 	return commits
 }
 
+// parseGitLogRecord parses one logFormat record; ok is false for blank or
+// malformed records.
+func parseGitLogRecord(record string) (CommitInfo, bool) {
+	record = strings.TrimSpace(record)
+	if record == "" {
+		return CommitInfo{}, false
+	}
+	parts := strings.SplitN(record, "\x1e", 4)
+	if len(parts) < 4 {
+		return CommitInfo{}, false
+	}
+	return newCommitInfo(parts[0], parts[1], formatShellDate(parts[2]), parts[3]), true
+}
+
 // formatShellDate trims "%ai" output ("2025-01-15 10:30:00 -0500") to "2025-01-15 10:30".
 func formatShellDate(s string) string {
 	if len(s) >= 16 {
@@ -181,12 +119,17 @@ func firstLine(s string) string {
 }
 
 func commitToInfo(c *object.Commit) CommitInfo {
-	msg := strings.TrimRight(c.Message, "\n")
-	subject, body, _ := strings.Cut(msg, "\n")
+	return newCommitInfo(c.Hash.String(), c.Author.Name, c.Author.When.Format("2006-01-02 15:04"), c.Message)
+}
+
+// newCommitInfo is how both backends describe a commit: the hash
+// abbreviated to 7 characters, and the message's first line as its subject.
+func newCommitInfo(hash, author, date, message string) CommitInfo {
+	subject, body, _ := strings.Cut(strings.TrimRight(message, "\n"), "\n")
 	return CommitInfo{
-		Hash:    c.Hash.String()[:7],
-		Author:  c.Author.Name,
-		Date:    c.Author.When.Format("2006-01-02 15:04"),
+		Hash:    hash[:min(7, len(hash))],
+		Author:  author,
+		Date:    date,
 		Message: subject,
 		Body:    strings.TrimSpace(body),
 	}
@@ -1,6 +1,8 @@
 package git
 
 import (
+	"errors"
+	"path/filepath"
 	"testing"
 
 	gogit "github.com/go-git/go-git/v6"
@@ -24,7 +26,7 @@ func TestLog(t *testing.T) {
 	}
 
 	t.Run("returns all commits in order", func(t *testing.T) {
-		commits, err := repo.Log("HEAD", 0, nil)
+		commits, err := repo.Log("HEAD", 0, Pathspec{})
 		if err != nil {
 			t.Fatalf("Log() error = %v", err)
 		}
@@ -40,7 +42,7 @@ func TestLog(t *testing.T) {
 	})
 
 	t.Run("maxCount limits results", func(t *testing.T) {
-		commits, err := repo.Log("HEAD", 2, nil)
+		commits, err := repo.Log("HEAD", 2, Pathspec{})
 		if err != nil {
 			t.Fatalf("Log() error = %v", err)
 		}
@@ -50,7 +52,7 @@ func TestLog(t *testing.T) {
 	})
 
 	t.Run("hash is 7 chars", func(t *testing.T) {
-		commits, err := repo.Log("HEAD", 1, nil)
+		commits, err := repo.Log("HEAD", 1, Pathspec{})
 		if err != nil {
 			t.Fatalf("Log() error = %v", err)
 		}
@@ -74,7 +76,7 @@ func TestLog_Body(t *testing.T) {
 	}
 	testCommit(t, wt, "subject line\n\nBody paragraph one.\nBody paragraph two.")
 
-	commits, err := repo.Log("HEAD", 1, nil)
+	commits, err := repo.Log("HEAD", 1, Pathspec{})
 	if err != nil {
 		t.Fatalf("Log() error = %v", err)
 	}
@@ -89,7 +91,7 @@ func TestLog_Body(t *testing.T) {
 func TestLog_NoBody(t *testing.T) {
 	repo := setupTestRepo(t)
 
-	commits, err := repo.Log("HEAD", 1, nil)
+	commits, err := repo.Log("HEAD", 1, Pathspec{})
 	if err != nil {
 		t.Fatalf("Log() error = %v", err)
 	}
@@ -101,7 +103,7 @@ func TestLog_NoBody(t *testing.T) {
 func TestLog_BadRef(t *testing.T) {
 	repo := setupTestRepo(t)
 
-	_, err := repo.Log("nonexistent-ref", 0, nil)
+	_, err := repo.Log("nonexistent-ref", 0, Pathspec{})
 	if err == nil {
 		t.Fatal("expected error for bad ref, got nil")
 	}
@@ -141,7 +143,7 @@ func TestLogAll(t *testing.T) {
 	}
 	testCommit(t, wt, "feature commit")
 
-	commits, err := repo.LogAll(0, nil)
+	commits, err := repo.LogAll(0, Pathspec{})
 	if err != nil {
 		t.Fatalf("LogAll() error = %v", err)
 	}
@@ -161,7 +163,7 @@ func TestLogAll(t *testing.T) {
 func TestLogAll_MaxCount(t *testing.T) {
 	repo := setupTestRepo(t)
 
-	commits, err := repo.LogAll(1, nil)
+	commits, err := repo.LogAll(1, Pathspec{})
 	if err != nil {
 		t.Fatalf("LogAll() error = %v", err)
 	}
@@ -169,3 +171,91 @@ func TestLogAll_MaxCount(t *testing.T) {
 		t.Fatalf("expected 1 commit with maxCount=1, got %d", len(commits))
 	}
 }
+
+func TestLogSeq_StopsEarly(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+	commitFile(t, repo, "a.txt", "a\n", "Add a")
+	commitFile(t, repo, "b.txt", "b\n", "Add b")
+
+	var got []string
+	for c, err := range repo.LogSeq("HEAD", 0, Pathspec{}) {
+		if err != nil {
+			t.Fatalf("LogSeq() error = %v", err)
+		}
+		got = append(got, c.Message)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != "Add b" || got[1] != "Add a" {
+		t.Errorf("LogSeq() = %q, want the two newest commits", got)
+	}
+}
+
+func TestLogCLI(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+	commitFile(t, repo, "a.txt", "a\n", "Add a\n\nWith a body.")
+	commitFile(t, repo, "b.txt", "b\n", "Add b")
+	cli := newCLIBackend(repo.root)
+
+	commits, err := collectCommits(cli.Log("HEAD", 0, Pathspec{}))
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(commits) != 3 || commits[0].Message != "Add b" || commits[1].Body != "With a body." {
+		t.Fatalf("Log() = %+v, want 3 commits newest first", commits)
+	}
+
+	// Stopping early must not hang on the unread rest of git's output.
+	for c, err := range cli.Log("HEAD", 0, Pathspec{}) {
+		if err != nil || c.Message != "Add b" {
+			t.Fatalf("first commit = %+v, %v", c, err)
+		}
+		break
+	}
+
+	var refErr *RefError
+	if _, err := collectCommits(cli.Log("no-such-ref", 0, Pathspec{})); !errors.As(err, &refErr) {
+		t.Errorf("Log() of a bad ref error = %v, want a *RefError", err)
+	}
+}
+
+func TestLog_Pathspec(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	wt, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	for _, f := range []string{"src/a.go", "src/a_test.go", "docs/guide.md"} {
+		writeFile(t, repo.root, f, f)
+		if _, err := wt.Add(f); err != nil {
+			t.Fatalf("git add: %v", err)
+		}
+		testCommit(t, wt, "add "+f)
+	}
+
+	spec, err := ParsePathspec("", []string{":(glob)src/*.go", ":!*_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := repo.Log("HEAD", 0, spec)
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Message != "add src/a.go" {
+		t.Errorf("Log() = %+v, want only the src/a.go commit", commits)
+	}
+
+	// git log must read the spec the same way from any directory.
+	t.Chdir(filepath.Join(repo.root, "docs"))
+	shell, err := collectCommits(newCLIBackend(repo.root).Log("HEAD", 0, spec))
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(shell) != 1 || shell[0].Message != "add src/a.go" {
+		t.Errorf("git Log() = %+v, want only the src/a.go commit", shell)
+	}
+}
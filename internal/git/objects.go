@@ -0,0 +1,297 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrObjectNotFound is returned by ObjectReader.Read for a name that doesn't
+// resolve to an object, such as a path that doesn't exist at a commit.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectReader reads objects from a repository through one long-running
+// `git cat-file --batch-command`, rather than a git process per object.
+// Names are anything git resolves to an object: "HEAD:path", ":path" for
+// the index, "main^{tree}". The process runs in the reader's directory,
+// so in a linked worktree ":path" reads that worktree's own index. git
+// loads the index once per process, so an index read after the index file
+// has changed restarts the process first. If the process can't be started
+// or stops answering, each read falls back to a git process of its own.
+//
+// An ObjectReader is safe for concurrent use; reads are answered one at a
+// time.
+type ObjectReader struct {
+	dir string
+
+	mu       sync.Mutex
+	started  bool // the process has been started, or tried
+	answered bool // the process has answered a read
+	cmd      *exec.Cmd
+	in       io.WriteCloser
+	out      *bufio.Reader
+
+	index     string      // the index file, found when the process starts
+	indexStat os.FileInfo // the index file before the process started, or nil
+}
+
+// NewObjectReader returns a reader for the repository at dir. The git
+// process starts on the first read.
+func NewObjectReader(dir string) *ObjectReader {
+	return &ObjectReader{dir: dir}
+}
+
+// objectReaders holds the ObjectReader shared by everything reading a
+// repository, by directory.
+var objectReaders sync.Map
+
+// Objects returns the ObjectReader for the repository at dir that every
+// reader of it shares, so they all go through one git process. Repo.Close
+// stops it.
+func Objects(dir string) *ObjectReader {
+	if o, ok := objectReaders.Load(dir); ok {
+		return o.(*ObjectReader)
+	}
+	o, _ := objectReaders.LoadOrStore(dir, NewObjectReader(dir))
+	return o.(*ObjectReader)
+}
+
+// closeObjects stops the shared reader for dir, if one was made. The next
+// call to Objects makes a new one.
+func closeObjects(dir string) error {
+	if o, ok := objectReaders.LoadAndDelete(dir); ok {
+		return o.(*ObjectReader).Close()
+	}
+	return nil
+}
+
+// Read returns the contents of the blob name resolves to, or
+// ErrObjectNotFound.
+func (o *ObjectReader) Read(ctx context.Context, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if data, ok, err := batch(o, name, o.readContents); ok {
+		return data, err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "-e", name)
+	cmd.Dir = o.dir
+	if cmd.Run() != nil {
+		return nil, ErrObjectNotFound
+	}
+	cmd = exec.CommandContext(ctx, "git", "cat-file", "blob", name)
+	cmd.Dir = o.dir
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// ObjectIDs resolves each of names to an object ID, or "" for a name that
+// resolves to nothing.
+func (o *ObjectReader) ObjectIDs(ctx context.Context, names ...string) ([]string, error) {
+	ids := make([]string, len(names))
+	for i, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		id, ok, err := batch(o, name, o.readInfo)
+		if !ok {
+			return objectIDsShell(ctx, o.dir, names)
+		}
+		if err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// objectIDsShell resolves names in a git process of its own.
+func objectIDsShell(ctx context.Context, dir string, names []string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch-check=%(objectname)")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(names, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file --batch-check: %w", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if len(lines) != len(names) {
+		return nil, fmt.Errorf("git cat-file --batch-check: %d answers for %d objects", len(lines), len(names))
+	}
+	for i, line := range lines {
+		if strings.HasSuffix(line, " missing") || strings.HasSuffix(line, " ambiguous") {
+			lines[i] = ""
+		}
+	}
+	return lines, nil
+}
+
+// batch sends one command about name to the long-running process and reads
+// the answer with read. ok is false if the process can't be used, in which
+// case the caller falls back to a process of its own.
+func batch[T any](o *ObjectReader, name string, read func(string) (T, error)) (v T, ok bool, err error) {
+	// A newline would end the command early; such names take the fallback.
+	if strings.ContainsAny(name, "\n\x00") {
+		return v, false, nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if strings.HasPrefix(name, ":") && o.cmd != nil && o.indexChanged() {
+		o.stop()
+		o.started = false
+	}
+	if !o.start() {
+		return v, false, nil
+	}
+	v, err = read(name)
+	var protoErr *batchError
+	if errors.As(err, &protoErr) {
+		// A process that has worked before is worth restarting on the next
+		// read: git exits on some malformed names. One that never answered
+		// won't, as with a git too old for --batch-command.
+		o.stop()
+		o.started = !o.answered
+		return v, false, nil
+	}
+	o.answered = true
+	return v, true, err
+}
+
+// batchError is a failure to talk to the long-running process, as opposed
+// to an answer from it.
+type batchError struct{ err error }
+
+func (e *batchError) Error() string { return "git cat-file --batch-command: " + e.err.Error() }
+func (e *batchError) Unwrap() error { return e.err }
+
+// start starts the process if it isn't running and hasn't been given up
+// on, and reports whether it's running.
+func (o *ObjectReader) start() bool {
+	if o.started {
+		return o.cmd != nil
+	}
+	o.started = true
+	o.statIndex()
+	cmd := exec.Command("git", "cat-file", "--batch-command")
+	cmd.Dir = o.dir
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return false
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return false
+	}
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	o.cmd, o.in, o.out = cmd, in, bufio.NewReader(out)
+	return true
+}
+
+// statIndex records the index file as it is before the process loads it.
+// Its path honours GIT_INDEX_FILE and linked worktrees' own indexes.
+func (o *ObjectReader) statIndex() {
+	if o.index == "" {
+		cmd := exec.Command("git", "rev-parse", "--git-path", "index")
+		cmd.Dir = o.dir
+		out, err := cmd.Output()
+		if err != nil {
+			return
+		}
+		o.index = strings.TrimSpace(string(out))
+		if !filepath.IsAbs(o.index) {
+			o.index = filepath.Join(o.dir, o.index)
+		}
+	}
+	o.indexStat, _ = os.Stat(o.index)
+}
+
+// indexChanged reports whether the index file may have changed since the
+// process started. git replaces the file rather than rewriting it, so a
+// change shows as a different file even within the mtime's resolution.
+func (o *ObjectReader) indexChanged() bool {
+	if o.index == "" {
+		return true
+	}
+	fi, err := os.Stat(o.index)
+	if err != nil || o.indexStat == nil {
+		return (err == nil) != (o.indexStat != nil)
+	}
+	return !os.SameFile(fi, o.indexStat) || !fi.ModTime().Equal(o.indexStat.ModTime()) || fi.Size() != o.indexStat.Size()
+}
+
+func (o *ObjectReader) stop() {
+	if o.cmd == nil {
+		return
+	}
+	o.in.Close()
+	o.cmd.Process.Kill()
+	o.cmd.Wait()
+	o.cmd, o.in, o.out = nil, nil, nil
+}
+
+// Close stops the git process. Reads after Close each start a git process
+// of their own.
+func (o *ObjectReader) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stop()
+	o.started, o.answered = true, false
+	return nil
+}
+
+// header sends command and parses the "<oid> <type> <size>" line that
+// answers it.
+func (o *ObjectReader) header(command, name string) (id string, size int64, err error) {
+	if _, err := fmt.Fprintf(o.in, "%s %s\n", command, name); err != nil {
+		return "", 0, &batchError{err}
+	}
+	line, err := o.out.ReadString('\n')
+	if err != nil {
+		return "", 0, &batchError{err}
+	}
+	line = strings.TrimSuffix(line, "\n")
+	if strings.HasSuffix(line, " missing") || strings.HasSuffix(line, " ambiguous") {
+		return "", 0, ErrObjectNotFound
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return "", 0, &batchError{fmt.Errorf("unexpected answer %q", line)}
+	}
+	size, err = strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return "", 0, &batchError{fmt.Errorf("unexpected answer %q", line)}
+	}
+	return fields[0], size, nil
+}
+
+func (o *ObjectReader) readInfo(name string) (string, error) {
+	id, _, err := o.header("info", name)
+	return id, err
+}
+
+func (o *ObjectReader) readContents(name string) ([]byte, error) {
+	_, size, err := o.header("contents", name)
+	if err != nil {
+		return nil, err
+	}
+	// The contents are followed by a newline of the protocol's own.
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(o.out, data); err != nil {
+		return nil, &batchError{err}
+	}
+	return data[:size], nil
+}
@@ -0,0 +1,193 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestObjectReader(t *testing.T) {
+	repo := setupTestRepo(t)
+	gitAdd := func(dir, path string) {
+		t.Helper()
+		if out, err := exec.Command("git", "-C", dir, "add", "--", path).CombinedOutput(); err != nil {
+			t.Fatalf("git add: %v: %s", err, out)
+		}
+	}
+	// No trailing newline, and a NUL: the reader mustn't trim or stop early.
+	writeFile(t, repo.root, "bin.dat", "a\x00b\n\nc")
+	gitAdd(repo.root, "bin.dat")
+
+	ctx := context.Background()
+	for _, name := range []string{"Read", "Fallback"} {
+		t.Run(name, func(t *testing.T) {
+			o := NewObjectReader(repo.root)
+			defer o.Close()
+			if name == "Fallback" {
+				o.Close()
+			}
+
+			tests := []struct {
+				name string
+				want string
+				err  error
+			}{
+				{"HEAD:README.md", "# test repo\n", nil},
+				{":bin.dat", "a\x00b\n\nc", nil},
+				{":README.md", "# test repo\n", nil},
+				{"HEAD:bin.dat", "", ErrObjectNotFound},
+				{"nosuchref:README.md", "", ErrObjectNotFound},
+			}
+			for _, tt := range tests {
+				got, err := o.Read(ctx, tt.name)
+				if !errors.Is(err, tt.err) || string(got) != tt.want {
+					t.Errorf("Read(%q) = %q, %v; want %q, %v", tt.name, got, err, tt.want, tt.err)
+				}
+			}
+
+			ids, err := o.ObjectIDs(ctx, "HEAD^{tree}", "HEAD:missing", ":bin.dat")
+			if err != nil {
+				t.Fatalf("ObjectIDs() error = %v", err)
+			}
+			out, err := exec.Command("git", "-C", repo.root, "rev-parse", "HEAD^{tree}", ":bin.dat").Output()
+			if err != nil {
+				t.Fatalf("git rev-parse: %v", err)
+			}
+			want := strings.Fields(string(out))
+			if ids[0] != want[0] || ids[1] != "" || ids[2] != want[1] {
+				t.Errorf("ObjectIDs() = %q, want [%s \"\" %s]", ids, want[0], want[1])
+			}
+		})
+	}
+
+	t.Run("Concurrent", func(t *testing.T) {
+		o := NewObjectReader(repo.root)
+		defer o.Close()
+		var wg sync.WaitGroup
+		for i := range 32 {
+			wg.Go(func() {
+				name, want := "HEAD:README.md", "# test repo\n"
+				if i%2 == 1 {
+					name, want = ":bin.dat", "a\x00b\n\nc"
+				}
+				if got, err := o.Read(ctx, name); err != nil || string(got) != want {
+					t.Errorf("Read(%q) = %q, %v; want %q", name, got, err, want)
+				}
+			})
+		}
+		wg.Wait()
+	})
+
+	t.Run("LinkedWorktree", func(t *testing.T) {
+		setCommitIdentity(t)
+		path := repo.DefaultWorktreePath("objects")
+		t.Cleanup(func() { os.RemoveAll(path) })
+		if err := repo.AddWorktree(path, "objects", true, ""); err != nil {
+			t.Fatalf("AddWorktree() error = %v", err)
+		}
+		writeFile(t, path, "README.md", "# staged in the worktree\n")
+		gitAdd(path, "README.md")
+
+		o := NewObjectReader(path)
+		defer o.Close()
+		if got, err := o.Read(ctx, ":README.md"); err != nil || string(got) != "# staged in the worktree\n" {
+			t.Errorf("Read(:README.md) in the worktree = %q, %v; want its own index's version", got, err)
+		}
+		if _, err := o.Read(ctx, ":bin.dat"); !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("Read(:bin.dat) in the worktree error = %v, want ErrObjectNotFound: it's only in the main index", err)
+		}
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		o := NewObjectReader(repo.root)
+		defer o.Close()
+		if _, err := o.Read(ctx, "HEAD:README.md"); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		// Kill the process behind the reader's back; it should start another.
+		o.mu.Lock()
+		o.cmd.Process.Kill()
+		o.mu.Unlock()
+		for i := range 3 {
+			if got, err := o.Read(ctx, "HEAD:README.md"); err != nil || string(got) != "# test repo\n" {
+				t.Errorf("Read() #%d after the process died = %q, %v", i, got, err)
+			}
+		}
+		o.mu.Lock()
+		running := o.cmd != nil
+		o.mu.Unlock()
+		if !running {
+			t.Error("reader gave up on the process after it had worked")
+		}
+	})
+
+	t.Run("IndexChanges", func(t *testing.T) {
+		o := NewObjectReader(repo.root)
+		defer o.Close()
+		if got, err := o.Read(ctx, ":README.md"); err != nil || string(got) != "# test repo\n" {
+			t.Fatalf("Read(:README.md) = %q, %v", got, err)
+		}
+		writeFile(t, repo.root, "README.md", "# staged since\n")
+		gitAdd(repo.root, "README.md")
+
+		if got, err := o.Read(ctx, ":README.md"); err != nil || string(got) != "# staged since\n" {
+			t.Errorf("Read(:README.md) after staging = %q, %v; want the new index's version", got, err)
+		}
+		ids, err := o.ObjectIDs(ctx, ":README.md")
+		if err != nil {
+			t.Fatalf("ObjectIDs() error = %v", err)
+		}
+		out, err := exec.Command("git", "-C", repo.root, "rev-parse", ":README.md").Output()
+		if err != nil {
+			t.Fatalf("git rev-parse: %v", err)
+		}
+		if want := strings.TrimSpace(string(out)); ids[0] != want {
+			t.Errorf("ObjectIDs(:README.md) after staging = %q, want %q", ids[0], want)
+		}
+
+		// An unchanged index keeps the process.
+		o.mu.Lock()
+		cmd := o.cmd
+		o.mu.Unlock()
+		o.Read(ctx, ":README.md")
+		o.Read(ctx, "HEAD:README.md")
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if o.cmd != cmd || cmd == nil {
+			t.Error("reader restarted the process without the index changing")
+		}
+	})
+}
+
+func TestObjects_SharedUntilClose(t *testing.T) {
+	repo := setupTestRepo(t)
+	o := Objects(repo.root)
+	if Objects(repo.root) != o {
+		t.Fatal("Objects() returned a second reader for the same repository")
+	}
+	if _, err := newCLIBackend(repo.root).content("HEAD:")("README.md"); err != nil {
+		t.Fatalf("read through the git backend: %v", err)
+	}
+	o.mu.Lock()
+	running := o.cmd != nil
+	o.mu.Unlock()
+	if !running {
+		t.Fatal("the git backend didn't read through the shared reader")
+	}
+
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.cmd != nil {
+		t.Error("Close() left the git process running")
+	}
+	if Objects(repo.root) == o {
+		t.Error("Objects() after Close() returned the closed reader")
+	}
+}
@@ -0,0 +1,276 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Pathspec selects repository paths the way git's pathspecs do. Each
+// pattern matches a path it equals, a directory the path is under, or, if
+// it has wildcards, any path it matches as a glob in which * and ? also
+// match "/". Magic changes that, in the short form (":!", ":^" to exclude,
+// ":/" for the top of the tree) or the long one (":(exclude,icase)..."):
+//
+//	top      the pattern is relative to the root, not the current directory
+//	exclude  drop what the pattern matches; with only excludes, everything
+//	         else is selected
+//	icase    match case-insensitively
+//	glob     * and ? stop at "/", and ** matches across directories
+//	literal  no wildcards
+//
+// The zero Pathspec selects everything.
+type Pathspec struct {
+	items []pathspecItem
+}
+
+type pathspecItem struct {
+	pattern string // from the root; "" for the whole tree
+	exclude bool
+	icase   bool
+	glob    bool
+	literal bool
+}
+
+// ParsePathspec reads args as pathspecs relative to prefix, the current
+// directory's path from the repository root ("" at the root).
+func ParsePathspec(prefix string, args []string) (Pathspec, error) {
+	var ps Pathspec
+	for _, arg := range args {
+		item, err := parsePathspecItem(prefix, arg)
+		if err != nil {
+			return Pathspec{}, err
+		}
+		ps.items = append(ps.items, item)
+	}
+	return ps, nil
+}
+
+func parsePathspecItem(prefix, arg string) (pathspecItem, error) {
+	var item pathspecItem
+	top := false
+	pattern := arg
+	switch {
+	case strings.HasPrefix(arg, ":("):
+		end := strings.IndexByte(arg, ')')
+		if end < 0 {
+			return item, fmt.Errorf("invalid pathspec %q: missing ')'", arg)
+		}
+		for word := range strings.SplitSeq(arg[2:end], ",") {
+			switch strings.TrimSpace(word) {
+			case "top":
+				top = true
+			case "exclude":
+				item.exclude = true
+			case "icase":
+				item.icase = true
+			case "glob":
+				item.glob = true
+			case "literal":
+				item.literal = true
+			case "":
+			default:
+				return item, fmt.Errorf("invalid pathspec magic %q in %q", word, arg)
+			}
+		}
+		pattern = arg[end+1:]
+	case strings.HasPrefix(arg, ":"):
+		i := 1
+	short:
+		for ; i < len(arg); i++ {
+			switch arg[i] {
+			case '/':
+				top = true
+			case '!', '^':
+				item.exclude = true
+			case ':':
+				i++
+				break short
+			default:
+				break short
+			}
+		}
+		pattern = arg[i:]
+	}
+	if item.glob && item.literal {
+		return item, fmt.Errorf("invalid pathspec %q: glob and literal can't be combined", arg)
+	}
+
+	if top {
+		prefix = ""
+	}
+	if item.pattern = path.Join(prefix, pattern); item.pattern == "." {
+		item.pattern = ""
+	}
+	return item, nil
+}
+
+// Pathspec reads args as pathspecs relative to the current directory, as
+// git does.
+func (r *Repo) Pathspec(args []string) (Pathspec, error) {
+	return ParsePathspec(r.cwdPrefix(), args)
+}
+
+// cwdPrefix is the current directory relative to the worktree root, or ""
+// outside it.
+func (r *Repo) cwdPrefix() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(canonicalPath(r.root), canonicalPath(cwd))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// IsEmpty reports whether ps selects everything.
+func (ps Pathspec) IsEmpty() bool {
+	return len(ps.items) == 0
+}
+
+// Match reports whether ps selects file, a slash-separated path from the
+// repository root.
+func (ps Pathspec) Match(file string) bool {
+	included, hasInclude := false, false
+	for _, item := range ps.items {
+		if item.exclude {
+			if item.match(file) {
+				return false
+			}
+			continue
+		}
+		hasInclude = true
+		included = included || item.match(file)
+	}
+	return included || !hasInclude
+}
+
+// Args renders ps for git on the command line. Every pattern is marked
+// top, so git reads them the same whatever its working directory.
+func (ps Pathspec) Args() []string {
+	args := make([]string, len(ps.items))
+	for i, item := range ps.items {
+		magic := []string{"top"}
+		for _, m := range []struct {
+			set  bool
+			name string
+		}{{item.exclude, "exclude"}, {item.icase, "icase"}, {item.glob, "glob"}, {item.literal, "literal"}} {
+			if m.set {
+				magic = append(magic, m.name)
+			}
+		}
+		args[i] = ":(" + strings.Join(magic, ",") + ")" + item.pattern
+	}
+	return args
+}
+
+func (item pathspecItem) match(file string) bool {
+	pattern := item.pattern
+	if item.icase {
+		pattern, file = strings.ToLower(pattern), strings.ToLower(file)
+	}
+	if pattern == "" || file == pattern || strings.HasPrefix(file, pattern+"/") {
+		return true
+	}
+	return !item.literal && hasWildcard(pattern) && wildmatch(pattern, file, item.glob)
+}
+
+func hasWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// wildmatch matches name against a glob. [...] classes take ranges and a
+// leading ! or ^ to negate; a backslash escapes the next character. Unless
+// pathname is set, * and ? match any character, "/" included, as in git's
+// default pathspecs. With it they stop at "/", and a ** between slashes (or
+// at either end) matches any number of directories.
+func wildmatch(pattern, name string, pathname bool) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			stars := len(pattern) - len(strings.TrimLeft(pattern, "*"))
+			rest := pattern[stars:]
+			anyDirs := !pathname
+			if pathname && stars >= 2 {
+				// ** counts only as a whole path component.
+				anyDirs = rest == "" || rest[0] == '/'
+				if anyDirs && rest != "" && wildmatch(rest[1:], name, true) {
+					return true // "**/" matching no directories
+				}
+			}
+			for i := 0; i <= len(name); i++ {
+				if wildmatch(rest, name[i:], pathname) {
+					return true
+				}
+				if i < len(name) && name[i] == '/' && !anyDirs {
+					return false
+				}
+			}
+			return false
+		case '?':
+			if name == "" || (pathname && name[0] == '/') {
+				return false
+			}
+		case '[':
+			ok, width := matchClass(pattern, name)
+			if width > 0 {
+				if !ok || (pathname && name[0] == '/') {
+					return false
+				}
+				pattern, name = pattern[width:], name[1:]
+				continue
+			}
+			if name == "" || name[0] != '[' {
+				return false
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if name == "" || name[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return name == ""
+}
+
+// matchClass matches the first byte of name against the [...] class at the
+// start of pattern. It returns the class's width in bytes, or 0 if the
+// class isn't closed and the "[" is literal.
+func matchClass(pattern, name string) (bool, int) {
+	i := 1
+	negate := false
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negate = true
+		i++
+	}
+	matched := false
+	for first := true; i < len(pattern); first = false {
+		if pattern[i] == ']' && !first {
+			return name != "" && matched != negate, i + 1
+		}
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			i += 2
+		}
+		if name != "" && lo <= name[0] && name[0] <= hi {
+			matched = true
+		}
+		i++
+	}
+	return false, 0
+}
@@ -0,0 +1,145 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPathspec_Match(t *testing.T) {
+	files := []string{"README.md", "cmd/root.go", "cmd/stage.go", "internal/git/status.go", "internal/git/status_test.go", "docs/a[1].md"}
+	tests := []struct {
+		prefix string
+		args   []string
+		want   []string
+	}{
+		{"", nil, files},
+		{"", []string{"cmd/root.go"}, []string{"cmd/root.go"}},
+		{"", []string{"internal/"}, []string{"internal/git/status.go", "internal/git/status_test.go"}},
+		{"", []string{"inter"}, nil},
+		{"", []string{"*.go"}, []string{"cmd/root.go", "cmd/stage.go", "internal/git/status.go", "internal/git/status_test.go"}},
+		{"", []string{"cmd/s?age.go", "README.*"}, []string{"README.md", "cmd/stage.go"}},
+		{"", []string{"internal/*[!t].go"}, []string{"internal/git/status.go"}},
+		{"", []string{`docs/a\[1].md`}, []string{"docs/a[1].md"}},
+		{"", []string{"docs/a[1].md"}, []string{"docs/a[1].md"}},
+		{"", []string{":!*_test.go", "internal"}, []string{"internal/git/status.go"}},
+		{"", []string{":^cmd"}, []string{"README.md", "internal/git/status.go", "internal/git/status_test.go", "docs/a[1].md"}},
+		{"cmd", []string{"."}, []string{"cmd/root.go", "cmd/stage.go"}},
+		{"cmd", []string{"../README.md", "stage.go"}, []string{"README.md", "cmd/stage.go"}},
+		{"cmd", []string{"../.."}, nil},
+		{"", []string{":(glob)*.go"}, nil},
+		{"", []string{":(glob)cmd/*.go"}, []string{"cmd/root.go", "cmd/stage.go"}},
+		{"", []string{":(glob)**/status*.go"}, []string{"internal/git/status.go", "internal/git/status_test.go"}},
+		{"", []string{":(glob)internal/**/*_test.go"}, []string{"internal/git/status_test.go"}},
+		{"", []string{":(glob)internal"}, []string{"internal/git/status.go", "internal/git/status_test.go"}},
+		{"", []string{":(icase)readme.MD"}, []string{"README.md"}},
+		{"", []string{":(icase,glob)CMD/*"}, []string{"cmd/root.go", "cmd/stage.go"}},
+		{"", []string{":(literal)docs/a[1].md", ":(literal)*.md"}, []string{"docs/a[1].md"}},
+		{"cmd", []string{":(top)README.md", ":/docs"}, []string{"README.md", "docs/a[1].md"}},
+		{"cmd", []string{":(exclude)root.go"}, []string{"README.md", "cmd/stage.go", "internal/git/status.go", "internal/git/status_test.go", "docs/a[1].md"}},
+		{"", []string{":(exclude,glob)**/*_test.go", ":!:README.md"}, []string{"cmd/root.go", "cmd/stage.go", "internal/git/status.go", "docs/a[1].md"}},
+	}
+	for _, tt := range tests {
+		spec, err := ParsePathspec(tt.prefix, tt.args)
+		if err != nil {
+			t.Fatalf("ParsePathspec(%q, %q) error = %v", tt.prefix, tt.args, err)
+		}
+		var got []string
+		for _, f := range files {
+			if spec.Match(f) {
+				got = append(got, f)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePathspec(%q, %q) matched %q, want %q", tt.prefix, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestParsePathspec_Invalid(t *testing.T) {
+	for _, arg := range []string{":(bogus)x", ":(glob,literal)x", ":(top"} {
+		if _, err := ParsePathspec("", []string{arg}); err == nil {
+			t.Errorf("ParsePathspec(%q) succeeded, want an error", arg)
+		}
+	}
+}
+
+func TestPathspec_Args(t *testing.T) {
+	spec, err := ParsePathspec("sub", []string{"a.go", ":!*_test.go", ":(icase,glob)**/X"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{":(top)sub/a.go", ":(top,exclude)sub/*_test.go", ":(top,icase,glob)sub/**/X"}
+	if got := spec.Args(); !slices.Equal(got, want) {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+}
+
+// TestPathspec_MatchesGit checks Match against git ls-files, which is the
+// definition of what a pathspec selects.
+func TestPathspec_MatchesGit(t *testing.T) {
+	repo := setupTestRepo(t)
+	files := []string{"Doc/R.md", "src/x.go", "src/deep/y.go", "src/deep/README.md", "top.go"}
+	for _, f := range files {
+		writeFile(t, repo.root, f, "x\n")
+	}
+	specs := [][]string{
+		{"src/*.go"}, {":(glob)src/*"}, {":(glob)src"}, {"s?c"}, {":(glob)s*"},
+		{":(icase)doc"}, {":(icase,glob)**/readme.md"}, {":(literal)src/*"},
+		{"*.md", ":!Doc"}, {":(glob)**/*.go", ":(exclude)src/deep"}, {"src/[d-e]*"},
+	}
+	for _, args := range specs {
+		cmd := exec.Command("git", append([]string{"ls-files", "--others", "--"}, args...)...)
+		cmd.Dir = repo.root
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git ls-files %q: %v", args, err)
+		}
+		want := strings.Fields(string(out))
+		spec, err := ParsePathspec("", args)
+		if err != nil {
+			t.Fatalf("ParsePathspec(%q) error = %v", args, err)
+		}
+		var got []string
+		for _, f := range files {
+			if spec.Match(f) {
+				got = append(got, f)
+			}
+		}
+		slices.Sort(got)
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("%q matched %q, git ls-files says %q", args, got, want)
+		}
+	}
+}
+
+func TestStatusFiles_Pathspec(t *testing.T) {
+	repo := setupTestRepo(t)
+	writeFile(t, repo.root, "README.md", "changed\n")
+	writeFile(t, repo.root, "sub/a.go", "package sub\n")
+	writeFile(t, repo.root, "sub/a_test.go", "package sub\n")
+
+	t.Chdir(filepath.Join(repo.root, "sub"))
+	spec, err := repo.Pathspec([]string{".", ":!*_test.go"})
+	if err != nil {
+		t.Fatalf("Pathspec() error = %v", err)
+	}
+	files, err := repo.StatusFiles(spec)
+	if err != nil {
+		t.Fatalf("StatusFiles() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "sub/a.go" {
+		t.Errorf("StatusFiles() = %+v, want only sub/a.go", files)
+	}
+
+	all, err := repo.StatusFiles(Pathspec{})
+	if err != nil {
+		t.Fatalf("StatusFiles() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("StatusFiles(everything) = %+v, want 3 files", all)
+	}
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RebaseActions are the todo commands rift rebase offers, in the order the
+// TUI cycles through them.
+var RebaseActions = []string{"pick", "reword", "edit", "squash", "fixup", "drop"}
+
+// RebaseStep is one line of an interactive rebase todo list.
+type RebaseStep struct {
+	Action  string `json:"action"`
+	Hash    string `json:"hash"`
+	Message string `json:"message"`
+}
+
+// RebaseTodo returns the default todo for rebasing HEAD onto upstream: every
+// non-merge commit in upstream..HEAD, oldest first, picked.
+func (r *Repo) RebaseTodo(upstream string) ([]RebaseStep, error) {
+	if err := r.VerifyRef(upstream); err != nil {
+		return nil, err
+	}
+	commits, err := r.logRange("--no-merges", "--reverse", upstream+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]RebaseStep, len(commits))
+	for i, c := range commits {
+		steps[i] = RebaseStep{Action: "pick", Hash: c.Hash, Message: c.Message}
+	}
+	return steps, nil
+}
+
+// ValidateRebaseTodo rejects todos git would refuse: unknown actions and a
+// squash or fixup with no earlier commit to fold into.
+func ValidateRebaseTodo(steps []RebaseStep) error {
+	kept := false
+	for _, s := range steps {
+		switch s.Action {
+		case "pick", "reword", "edit":
+			kept = true
+		case "squash", "fixup":
+			if !kept {
+				return fmt.Errorf("cannot %s %s: no earlier commit to fold into", s.Action, s.Hash)
+			}
+		case "drop":
+		default:
+			return fmt.Errorf("unknown rebase action %q for %s", s.Action, s.Hash)
+		}
+	}
+	return nil
+}
+
+// FormatRebaseTodo renders steps in git's todo file format.
+func FormatRebaseTodo(steps []RebaseStep) string {
+	var b strings.Builder
+	for _, s := range steps {
+		fmt.Fprintf(&b, "%s %s %s\n", s.Action, s.Hash, s.Message)
+	}
+	return b.String()
+}
+
+// Rebase runs git rebase -i onto upstream with steps as the todo, handed to
+// git through GIT_SEQUENCE_EDITOR. It is attached to the terminal so reword
+// and squash can open the user's editor, and edit stops or conflicts leave
+// the rebase in progress for the user to continue.
+func (r *Repo) Rebase(upstream string, steps []RebaseStep) error {
+	if err := ValidateRebaseTodo(steps); err != nil {
+		return err
+	}
+
+	todo, err := os.CreateTemp("", "rift-rebase-todo-*")
+	if err != nil {
+		return fmt.Errorf("create rebase todo: %w", err)
+	}
+	defer os.Remove(todo.Name())
+	if _, err := todo.WriteString(FormatRebaseTodo(steps)); err != nil {
+		todo.Close()
+		return fmt.Errorf("write rebase todo: %w", err)
+	}
+	if err := todo.Close(); err != nil {
+		return fmt.Errorf("write rebase todo: %w", err)
+	}
+
+	cmd := exec.Command("git", "-C", r.root, "rebase", "--interactive", upstream)
+	// git runs the sequence editor through the shell with the todo path
+	// appended, so this replaces git's todo with ours.
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todo.Name()))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if r.RebaseInProgress() {
+			return fmt.Errorf("rebase stopped: resolve the conflict, then run git rebase --continue (or --abort)")
+		}
+		return fmt.Errorf("git rebase: %w", err)
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestValidateRebaseTodo(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []RebaseStep
+		wantErr bool
+	}{
+		{"all picks", []RebaseStep{{Action: "pick", Hash: "a"}, {Action: "pick", Hash: "b"}}, false},
+		{"fixup after pick", []RebaseStep{{Action: "pick", Hash: "a"}, {Action: "fixup", Hash: "b"}}, false},
+		{"squash first", []RebaseStep{{Action: "squash", Hash: "a"}, {Action: "pick", Hash: "b"}}, true},
+		{"fixup after drop only", []RebaseStep{{Action: "drop", Hash: "a"}, {Action: "fixup", Hash: "b"}}, true},
+		{"unknown action", []RebaseStep{{Action: "exec", Hash: "a"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRebaseTodo(tt.steps)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRebaseTodo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatRebaseTodo(t *testing.T) {
+	got := FormatRebaseTodo([]RebaseStep{
+		{Action: "pick", Hash: "abc1234", Message: "Add a"},
+		{Action: "fixup", Hash: "def5678", Message: "fixup! Add a"},
+	})
+	want := "pick abc1234 Add a\nfixup def5678 fixup! Add a\n"
+	if got != want {
+		t.Errorf("FormatRebaseTodo() = %q, want %q", got, want)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+	t.Setenv("GIT_EDITOR", "true")
+
+	base, _ := repo.HeadHash()
+	commitFile(t, repo, "a.txt", "a\n", "Add a")
+	commitFile(t, repo, "b.txt", "b\n", "Add b")
+	commitFile(t, repo, "a.txt", "a\nmore\n", "Extend a")
+
+	steps, err := repo.RebaseTodo(base)
+	if err != nil {
+		t.Fatalf("RebaseTodo() error = %v", err)
+	}
+	if len(steps) != 3 || steps[0].Message != "Add a" || steps[2].Message != "Extend a" {
+		t.Fatalf("RebaseTodo() = %+v, want oldest first", steps)
+	}
+
+	// Fold "Extend a" into "Add a" and drop "Add b".
+	steps = []RebaseStep{steps[0], {Action: "fixup", Hash: steps[2].Hash}, {Action: "drop", Hash: steps[1].Hash}}
+	if err := repo.Rebase(base, steps); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+
+	commits, err := repo.CommitsSince(base)
+	if err != nil {
+		t.Fatalf("CommitsSince() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].Message != "Add a" {
+		t.Errorf("after rebase commits = %+v, want just Add a", commits)
+	}
+	if got := readFile(t, repo.root, "a.txt"); got != "a\nmore\n" {
+		t.Errorf("a.txt = %q, want the fixup folded in", got)
+	}
+}
@@ -0,0 +1,159 @@
+package git
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// renameThreshold is the minimum similarity, in percent, for an added file
+// to be reported as a rename or copy. It matches git's -M/-C default.
+const renameThreshold = 50
+
+// renameLimit caps the number of added files or sources considered, since
+// every pair has to be compared. It matches git's diff.renameLimit default.
+const renameLimit = 1000
+
+// contentFunc returns the content of a file on one side of a diff.
+type contentFunc func(path string) ([]byte, error)
+
+// detectRenames pairs added files with deleted files (renames) or modified
+// files (copies) whose old content is at least renameThreshold similar.
+// A deleted file matched more than once is a rename for the best match and a
+// copy for the rest, as in git. Consumed deletions are dropped from the result.
+func detectRenames(files []ChangedFile, oldContent, newContent contentFunc) []ChangedFile {
+	var added, sources []int
+	for i, f := range files {
+		switch f.Status {
+		case "Added":
+			added = append(added, i)
+		case "Deleted", "Modified":
+			sources = append(sources, i)
+		}
+	}
+	if len(added) == 0 || len(sources) == 0 || len(added) > renameLimit || len(sources) > renameLimit {
+		return files
+	}
+
+	oldCache := map[string][]byte{}
+	loadOld := func(path string) ([]byte, bool) {
+		if b, ok := oldCache[path]; ok {
+			return b, b != nil
+		}
+		b, err := oldContent(path)
+		if err != nil {
+			b = nil
+		}
+		oldCache[path] = b
+		return b, b != nil
+	}
+
+	type candidate struct {
+		added, source int
+		score         int
+	}
+	var candidates []candidate
+	for _, a := range added {
+		newData, err := newContent(files[a].Path)
+		if err != nil {
+			continue
+		}
+		for _, s := range sources {
+			oldData, ok := loadOld(files[s].Path)
+			if !ok {
+				continue
+			}
+			if score := similarity(oldData, newData); score >= renameThreshold {
+				candidates = append(candidates, candidate{added: a, source: s, score: score})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return files
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.score != cj.score {
+			return ci.score > cj.score
+		}
+		// Prefer renames over copies, then keep path order deterministic.
+		di, dj := files[ci.source].Status == "Deleted", files[cj.source].Status == "Deleted"
+		if di != dj {
+			return di
+		}
+		if files[ci.added].Path != files[cj.added].Path {
+			return files[ci.added].Path < files[cj.added].Path
+		}
+		return files[ci.source].Path < files[cj.source].Path
+	})
+
+	result := make([]ChangedFile, len(files))
+	copy(result, files)
+	matched := map[int]bool{}
+	renamed := map[int]bool{}
+	for _, c := range candidates {
+		if matched[c.added] {
+			continue
+		}
+		matched[c.added] = true
+		status := "Copied"
+		if files[c.source].Status == "Deleted" && !renamed[c.source] {
+			status = "Renamed"
+			renamed[c.source] = true
+		}
+		result[c.added] = ChangedFile{
+			Path:       files[c.added].Path,
+			OldPath:    files[c.source].Path,
+			Status:     status,
+			Similarity: c.score,
+		}
+	}
+
+	filtered := result[:0]
+	for i, f := range result {
+		if !renamed[i] {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
+// similarity scores how much of a survives in b, in percent, by counting the
+// bytes of lines the two share. Only identical content scores 100.
+func similarity(a, b []byte) int {
+	if bytes.Equal(a, b) {
+		return 100
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	counts := map[string]int{}
+	for _, line := range bytes.SplitAfter(a, []byte("\n")) {
+		counts[string(line)]++
+	}
+	common := 0
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if counts[string(line)] > 0 {
+			counts[string(line)]--
+			common += len(line)
+		}
+	}
+	return min(common*100/max(len(a), len(b)), 99)
+}
+
+func treeContent(t *object.Tree) contentFunc {
+	return func(path string) ([]byte, error) {
+		f, err := t.File(path)
+		if err != nil {
+			return nil, err
+		}
+		s, err := f.Contents()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+	}
+}
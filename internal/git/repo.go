@@ -1,9 +1,13 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	gogit "github.com/go-git/go-git/v6"
 )
@@ -12,17 +16,35 @@ type Repo struct {
 	repo           *gogit.Repository
 	root           string
 	linkedWorktree bool
+	bare           bool
+
+	backendOnce sync.Once
+	backend     Backend
+	backendErr  error
 }
 
 func OpenRepo() (*Repo, error) {
 	r, err := gogit.PlainOpenWithOptions(".", &gogit.PlainOpenOptions{
 		DetectDotGit: true,
 	})
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		return nil, ErrNotRepository
+	}
 	if err != nil {
 		return nil, fmt.Errorf("open repo: %w", err)
 	}
 
 	wt, err := r.Worktree()
+	if errors.Is(err, gogit.ErrIsBareRepository) || (err == nil && isBareGitFile(wt.Filesystem.Root())) {
+		// Bare-repo worktree layouts, including a .git file pointing at the
+		// bare repo: there's no worktree here, but worktree management still
+		// works from the git dir.
+		out, err := exec.Command("git", "rev-parse", "--absolute-git-dir").Output()
+		if err != nil {
+			return nil, fmt.Errorf("locate bare repo: %w", err)
+		}
+		return &Repo{repo: r, root: strings.TrimSpace(string(out)), bare: true}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("get worktree: %w", err)
 	}
@@ -35,6 +57,17 @@ func OpenRepo() (*Repo, error) {
 	}, nil
 }
 
+// isBareGitFile reports whether root's .git is a file pointing at a bare
+// repo rather than at a linked worktree. go-git follows the file without
+// checking and hands back a worktree either way, so ask git.
+func isBareGitFile(root string) bool {
+	if !isLinkedWorktree(root) {
+		return false
+	}
+	out, err := exec.Command("git", "-C", root, "rev-parse", "--is-bare-repository").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
 // isLinkedWorktree detects bare-repo worktree layouts where .git is a file
 // (containing a gitdir pointer) rather than a directory.
 func isLinkedWorktree(root string) bool {
@@ -45,6 +78,18 @@ func isLinkedWorktree(root string) bool {
 	return !info.IsDir()
 }
 
+// Close stops the git processes reading the repository. The Repo can
+// still be used; reads start them again.
+func (r *Repo) Close() error {
+	return closeObjects(r.root)
+}
+
 func (r *Repo) Root() string {
 	return r.root
 }
+
+// IsBare reports whether the repo was opened from a bare repository, where
+// Root is the git dir rather than a worktree.
+func (r *Repo) IsBare() bool {
+	return r.bare
+}
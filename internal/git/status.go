@@ -1,8 +1,11 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 type StatusFile struct {
@@ -11,36 +14,108 @@ type StatusFile struct {
 	WorktreeStatus string `json:"worktree_status"`
 }
 
-func (r *Repo) StatusFiles() ([]StatusFile, error) {
-	wt, err := r.repo.Worktree()
+// StatusFiles returns the files with staged or unstaged changes that spec
+// selects, sorted by path.
+func (r *Repo) StatusFiles(spec Pathspec) ([]StatusFile, error) {
+	b, err := r.Backend()
 	if err != nil {
-		return nil, fmt.Errorf("get worktree: %w", err)
+		return nil, err
 	}
-
-	status, err := wt.Status()
+	files, err := b.Status()
 	if err != nil {
-		return nil, fmt.Errorf("get status: %w", err)
+		return nil, err
 	}
 
-	files := []StatusFile{}
-	for path, s := range status {
-		staging := statusCodeToString(s.Staging)
-		worktree := statusCodeToString(s.Worktree)
-		if staging == "" && worktree == "" {
-			continue
+	filtered := []StatusFile{}
+	for _, f := range files {
+		if spec.Match(f.Path) {
+			filtered = append(filtered, f)
 		}
-		files = append(files, StatusFile{
-			Path:           path,
-			StagingStatus:  staging,
-			WorktreeStatus: worktree,
-		})
 	}
-
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].Path < files[j].Path
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Path < filtered[j].Path
 	})
+	return filtered, nil
+}
+
+// statusEntry is one path from `git status --porcelain=v2`.
+type statusEntry struct {
+	Path       string
+	OldPath    string // source of a staged rename or copy
+	Similarity int    // percent, for a rename or copy
+	Staging    string
+	Worktree   string
+}
+
+// parseStatusV2 parses `git status --porcelain=v2 -z`. Fields are
+// space-separated up to the path, which runs to the NUL, so paths with
+// spaces, quotes or newlines come through as they are.
+func parseStatusV2(out []byte) ([]statusEntry, error) {
+	var entries []statusEntry
+	records := bytes.Split(bytes.TrimSuffix(out, []byte{0}), []byte{0})
+	for i := 0; i < len(records); i++ {
+		rec := string(records[i])
+		if rec == "" {
+			continue
+		}
+		var n int
+		switch rec[0] {
+		case '#', '!': // headers, ignored files
+			continue
+		case '?':
+			path := strings.TrimPrefix(rec, "? ")
+			entries = append(entries, statusEntry{Path: path, Staging: "Untracked", Worktree: "Untracked"})
+			continue
+		case '1': // 1 XY sub mH mI mW hH hI path
+			n = 9
+		case '2': // 2 XY sub mH mI mW hH hI Xscore path, then origPath
+			n = 10
+		case 'u': // u XY sub m1 m2 m3 mW h1 h2 h3 path
+			n = 11
+		default:
+			return nil, fmt.Errorf("git status: unexpected entry %q", rec)
+		}
+		fields := strings.SplitN(rec, " ", n)
+		if len(fields) < n || len(fields[1]) != 2 {
+			return nil, fmt.Errorf("git status: malformed entry %q", rec)
+		}
+
+		e := statusEntry{
+			Path:     fields[len(fields)-1],
+			Staging:  porcelainCode(fields[1][0]),
+			Worktree: porcelainCode(fields[1][1]),
+		}
+		switch rec[0] {
+		case '2':
+			if i+1 >= len(records) {
+				return nil, fmt.Errorf("git status: %q has no source path", rec)
+			}
+			i++
+			e.OldPath = string(records[i])
+			e.Similarity, _ = strconv.Atoi(fields[8][1:])
+		case 'u':
+			e.Staging, e.Worktree = "Unmerged", "Unmerged"
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
 
-	return files, nil
+func porcelainCode(c byte) string {
+	switch c {
+	case 'M', 'T':
+		return "Modified"
+	case 'A':
+		return "Added"
+	case 'D':
+		return "Deleted"
+	case 'R':
+		return "Renamed"
+	case 'C':
+		return "Copied"
+	default:
+		return ""
+	}
 }
 
 func StatusChar(status string) string {
@@ -57,6 +132,8 @@ func StatusChar(status string) string {
 		return "C"
 	case "Untracked":
 		return "?"
+	case "Unmerged":
+		return "U"
 	default:
 		return " "
 	}
@@ -0,0 +1,39 @@
+package git
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestChangedFiles_Unmerged(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+	runGit(t, repo.root, "", "checkout", "-q", "-b", "other")
+	writeFile(t, repo.root, "README.md", "# other\n")
+	runGit(t, repo.root, "", "commit", "-q", "-am", "other")
+	runGit(t, repo.root, "", "checkout", "-q", "-")
+	writeFile(t, repo.root, "README.md", "# this\n")
+	runGit(t, repo.root, "", "commit", "-q", "-am", "this")
+	// The merge stops on the conflict, which is what the test wants.
+	exec.Command("git", "-C", repo.root, "merge", "-q", "other").Run()
+
+	for name, b := range backends(repo) {
+		for _, staged := range []bool{false, true} {
+			files, err := b.ChangedFiles(staged, true)
+			if err != nil {
+				t.Fatalf("%s: ChangedFiles(%v) error = %v", name, staged, err)
+			}
+			if want := []ChangedFile{{Path: "README.md", Status: "Unmerged"}}; !slices.Equal(files, want) {
+				t.Errorf("%s: ChangedFiles(%v) = %+v, want %+v", name, staged, files, want)
+			}
+		}
+		files, err := b.Status()
+		if err != nil {
+			t.Fatalf("%s: Status() error = %v", name, err)
+		}
+		if len(files) != 1 || StatusChar(files[0].StagingStatus) != "U" || StatusChar(files[0].WorktreeStatus) != "U" {
+			t.Errorf("%s: Status() = %+v, want README.md unmerged", name, files)
+		}
+	}
+}
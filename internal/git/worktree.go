@@ -0,0 +1,184 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WorktreeInfo describes one worktree as `git worktree list` reports it.
+// Bare is set for the bare repository entry of a bare-repo layout.
+type WorktreeInfo struct {
+	Path        string `json:"path"`
+	Branch      string `json:"branch,omitempty"`
+	Head        string `json:"head"`
+	Current     bool   `json:"current"`
+	Bare        bool   `json:"bare,omitempty"`
+	Detached    bool   `json:"detached,omitempty"`
+	Dirty       bool   `json:"dirty"`
+	Locked      bool   `json:"locked"`
+	LockReason  string `json:"lock_reason,omitempty"`
+	Prunable    bool   `json:"prunable"`
+	PruneReason string `json:"prune_reason,omitempty"`
+}
+
+// ListWorktrees returns every worktree of the repository, main worktree (or
+// bare repo) first, with each one's dirty state.
+func (r *Repo) ListWorktrees() ([]WorktreeInfo, error) {
+	out, err := exec.Command("git", "-C", r.root, "worktree", "list", "--porcelain", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree list: %w", err)
+	}
+	worktrees := parseWorktreeList(string(out))
+
+	current := canonicalPath(r.root)
+	for i := range worktrees {
+		wt := &worktrees[i]
+		wt.Current = canonicalPath(wt.Path) == current
+		if wt.Bare || wt.Prunable {
+			continue
+		}
+		status, err := exec.Command("git", "-C", wt.Path, "status", "--porcelain").Output()
+		wt.Dirty = err == nil && len(status) > 0
+	}
+	return worktrees, nil
+}
+
+// parseWorktreeList reads `git worktree list --porcelain -z`: NUL-terminated
+// "key value" attributes, with an empty attribute ending each worktree.
+func parseWorktreeList(out string) []WorktreeInfo {
+	worktrees := []WorktreeInfo{}
+	var wt *WorktreeInfo
+	for _, attr := range strings.Split(out, "\x00") {
+		if attr == "" {
+			if wt != nil {
+				worktrees = append(worktrees, *wt)
+				wt = nil
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(attr, " ")
+		if key == "worktree" {
+			wt = &WorktreeInfo{Path: value}
+			continue
+		}
+		if wt == nil {
+			continue
+		}
+		switch key {
+		case "HEAD":
+			wt.Head = value[:min(7, len(value))]
+		case "branch":
+			wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			wt.Bare = true
+		case "detached":
+			wt.Detached = true
+		case "locked":
+			wt.Locked = true
+			wt.LockReason = value
+		case "prunable":
+			wt.Prunable = true
+			wt.PruneReason = value
+		}
+	}
+	if wt != nil {
+		worktrees = append(worktrees, *wt)
+	}
+	return worktrees
+}
+
+// AddWorktree checks out branch in a new worktree at path. With newBranch
+// set, the branch is created first, starting at base (HEAD if empty).
+func (r *Repo) AddWorktree(path, branch string, newBranch bool, base string) error {
+	args := []string{"-C", r.root, "worktree", "add"}
+	if newBranch {
+		args = append(args, "-b", branch, path)
+		if base != "" {
+			args = append(args, base)
+		}
+	} else {
+		args = append(args, path, branch)
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// RemoveWorktree deletes the worktree at path. git refuses to remove a dirty
+// or locked worktree unless force is set.
+func (r *Repo) RemoveWorktree(path string, force bool) error {
+	args := []string{"-C", r.root, "worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, path)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree remove: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// PruneWorktrees drops administrative data for worktrees whose directories
+// are gone and returns git's report of what was removed.
+func (r *Repo) PruneWorktrees() ([]string, error) {
+	out, err := exec.Command("git", "-C", r.root, "worktree", "prune", "--verbose").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree prune: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	pruned := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			pruned = append(pruned, line)
+		}
+	}
+	return pruned, nil
+}
+
+// BranchExists reports whether name is a local branch or a branch on some
+// remote, i.e. whether `git worktree add` can check it out without creating
+// it first.
+func (r *Repo) BranchExists(name string) bool {
+	out, err := exec.Command("git", "-C", r.root, "for-each-ref", "--count=1", "--format=%(refname)",
+		"refs/heads/"+name, "refs/remotes/*/"+name).Output()
+	return err == nil && len(strings.TrimSpace(string(out))) > 0
+}
+
+// DefaultWorktreePath suggests where a worktree for branch should live. In
+// bare-repo layouts worktrees sit side by side named after their branch;
+// next to a regular checkout the repo name is used as a prefix.
+func (r *Repo) DefaultWorktreePath(branch string) string {
+	name := strings.ReplaceAll(branch, "/", "-")
+	if !r.bare && !r.linkedWorktree {
+		name = filepath.Base(r.root) + "-" + name
+	}
+	return filepath.Join(filepath.Dir(r.root), name)
+}
+
+// FindWorktree picks the worktree named by name: an exact branch or path
+// match, or a path whose last element is name.
+func FindWorktree(worktrees []WorktreeInfo, name string) (WorktreeInfo, bool) {
+	abs, _ := filepath.Abs(name)
+	for _, wt := range worktrees {
+		if wt.Branch == name || wt.Path == name || canonicalPath(wt.Path) == canonicalPath(abs) {
+			return wt, true
+		}
+	}
+	for _, wt := range worktrees {
+		if filepath.Base(wt.Path) == name {
+			return wt, true
+		}
+	}
+	return WorktreeInfo{}, false
+}
+
+// canonicalPath resolves symlinks so paths from git and from the
+// filesystem compare equal (e.g. /tmp vs /private/tmp on macOS).
+func canonicalPath(p string) string {
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		return resolved
+	}
+	return filepath.Clean(p)
+}
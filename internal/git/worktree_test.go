@@ -0,0 +1,157 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	out := "worktree /src/proj/.bare\x00bare\x00\x00" +
+		"worktree /src/proj/main\x00HEAD 0123456789abcdef\x00branch refs/heads/main\x00\x00" +
+		"worktree /src/proj/spike\x00HEAD fedcba9876543210\x00detached\x00locked on a USB drive\x00\x00" +
+		"worktree /src/proj/gone\x00HEAD 0123456789abcdef\x00branch refs/heads/feat/gone\x00prunable gitdir file points to non-existent location\x00\x00"
+
+	got := parseWorktreeList(out)
+	want := []WorktreeInfo{
+		{Path: "/src/proj/.bare", Bare: true},
+		{Path: "/src/proj/main", Branch: "main", Head: "0123456"},
+		{Path: "/src/proj/spike", Head: "fedcba9", Detached: true, Locked: true, LockReason: "on a USB drive"},
+		{Path: "/src/proj/gone", Branch: "feat/gone", Head: "0123456", Prunable: true, PruneReason: "gitdir file points to non-existent location"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseWorktreeList() returned %d worktrees, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("worktree %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWorktree_AddListRemove(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	path := repo.DefaultWorktreePath("feat/x")
+	if want := filepath.Join(filepath.Dir(repo.root), filepath.Base(repo.root)+"-feat-x"); path != want {
+		t.Errorf("DefaultWorktreePath() = %q, want %q", path, want)
+	}
+	t.Cleanup(func() { os.RemoveAll(path) })
+
+	if repo.BranchExists("feat/x") {
+		t.Fatal("BranchExists() = true before the branch was created")
+	}
+	if err := repo.AddWorktree(path, "feat/x", true, ""); err != nil {
+		t.Fatalf("AddWorktree() error = %v", err)
+	}
+	if !repo.BranchExists("feat/x") {
+		t.Error("BranchExists() = false after AddWorktree created it")
+	}
+	writeFile(t, path, "scratch.txt", "wip\n")
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees() error = %v", err)
+	}
+	if len(worktrees) != 2 {
+		t.Fatalf("ListWorktrees() = %+v, want main + 1", worktrees)
+	}
+	if !worktrees[0].Current || worktrees[0].Dirty {
+		t.Errorf("main worktree = %+v, want current and clean", worktrees[0])
+	}
+	wt, ok := FindWorktree(worktrees, "feat/x")
+	if !ok {
+		t.Fatalf("FindWorktree(feat/x) found nothing in %+v", worktrees)
+	}
+	if wt.Current || !wt.Dirty || wt.Head == "" {
+		t.Errorf("new worktree = %+v, want not current, dirty, with a HEAD", wt)
+	}
+	if _, ok := FindWorktree(worktrees, filepath.Base(path)); !ok {
+		t.Error("FindWorktree() by directory name found nothing")
+	}
+
+	if err := repo.RemoveWorktree(path, false); err == nil {
+		t.Fatal("RemoveWorktree() of a dirty worktree succeeded without force")
+	}
+	if err := repo.RemoveWorktree(path, true); err != nil {
+		t.Fatalf("RemoveWorktree(force) error = %v", err)
+	}
+	if worktrees, _ = repo.ListWorktrees(); len(worktrees) != 1 {
+		t.Errorf("ListWorktrees() after remove = %+v, want main only", worktrees)
+	}
+}
+
+func TestPruneWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	setCommitIdentity(t)
+
+	path := filepath.Join(t.TempDir(), "gone")
+	if err := repo.AddWorktree(path, "gone", true, ""); err != nil {
+		t.Fatalf("AddWorktree() error = %v", err)
+	}
+	os.RemoveAll(path)
+
+	worktrees, _ := repo.ListWorktrees()
+	if wt, ok := FindWorktree(worktrees, "gone"); !ok || !wt.Prunable {
+		t.Fatalf("deleted worktree = %+v, want prunable", wt)
+	}
+	pruned, err := repo.PruneWorktrees()
+	if err != nil {
+		t.Fatalf("PruneWorktrees() error = %v", err)
+	}
+	if len(pruned) != 1 {
+		t.Errorf("PruneWorktrees() = %q, want one entry", pruned)
+	}
+	if worktrees, _ = repo.ListWorktrees(); len(worktrees) != 1 {
+		t.Errorf("ListWorktrees() after prune = %+v, want main only", worktrees)
+	}
+}
+
+// TestOpenRepo_BareLayout covers the common layout of a bare clone in .bare
+// with a .git file pointing at it and worktrees beside it.
+func TestOpenRepo_BareLayout(t *testing.T) {
+	src := setupTestRepo(t)
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	git("clone", "-q", "--bare", src.root, ".bare")
+	writeFile(t, dir, ".git", "gitdir: ./.bare\n")
+	git("worktree", "add", "-q", "-b", "feature", "feature")
+
+	t.Chdir(dir)
+	repo, err := OpenRepo()
+	if err != nil {
+		t.Fatalf("OpenRepo() error = %v", err)
+	}
+	if !repo.IsBare() {
+		t.Errorf("IsBare() = false for %s", repo.Root())
+	}
+	if got, want := canonicalPath(repo.DefaultWorktreePath("feat/y")), canonicalPath(dir)+"/feat-y"; got != want {
+		t.Errorf("DefaultWorktreePath() = %q, want %q", got, want)
+	}
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees() error = %v", err)
+	}
+	if len(worktrees) != 2 || !worktrees[0].Bare || !worktrees[0].Current {
+		t.Fatalf("ListWorktrees() = %+v, want the current bare repo then feature", worktrees)
+	}
+
+	t.Chdir(filepath.Join(dir, "feature"))
+	repo, err = OpenRepo()
+	if err != nil {
+		t.Fatalf("OpenRepo() in worktree error = %v", err)
+	}
+	if repo.IsBare() {
+		t.Error("IsBare() = true inside a linked worktree")
+	}
+	if got, want := canonicalPath(repo.DefaultWorktreePath("other")), canonicalPath(dir)+"/other"; got != want {
+		t.Errorf("DefaultWorktreePath() = %q, want %q", got, want)
+	}
+}
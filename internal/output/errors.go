@@ -0,0 +1,28 @@
+package output
+
+import "io"
+
+// Error is the machine-readable form of a failure. Code is one of a fixed
+// set documented in the README; Path and Ref name the file or revision the
+// failure is about, when there is one.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+}
+
+// ErrorPayload is the object WriteError writes.
+type ErrorPayload struct {
+	Error Error `json:"error"`
+}
+
+// WriteError writes e as {"error": {...}}: indented in JSON mode, on one
+// line in NDJSON mode so it can end a stream of items.
+func WriteError(w io.Writer, mode Mode, e Error) error {
+	payload := ErrorPayload{e}
+	if mode == NDJSON {
+		return WriteNDJSON(w, []any{payload})
+	}
+	return WriteJSON(w, payload)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -16,12 +17,20 @@ const (
 	Interactive Mode = iota
 	Print
 	JSON
+	Template
+	NDJSON
 )
 
 func Detect(cmd *cobra.Command) Mode {
+	if n, _ := cmd.Flags().GetBool("ndjson"); n {
+		return NDJSON
+	}
 	if j, _ := cmd.Flags().GetBool("json"); j {
 		return JSON
 	}
+	if TemplateFormat(cmd) != "" {
+		return Template
+	}
 	if p, _ := cmd.Flags().GetBool("print"); p {
 		return Print
 	}
@@ -35,10 +44,39 @@ func IsTerminal() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
 }
 
+// WriteJSON writes v as indented JSON, with schema_version stamped on v or,
+// for a slice, on each of its items.
 func WriteJSON(w io.Writer, v any) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	return enc.Encode(v)
+	return enc.Encode(Stamp(v))
+}
+
+// WriteNDJSON writes each item as compact JSON on its own line, stamped
+// like WriteJSON's.
+func WriteNDJSON[T any](w io.Writer, items []T) error {
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		if err := enc.Encode(versioned{item}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamNDJSON writes items as WriteNDJSON does, each as soon as it is
+// produced, and stops at the first error from the sequence.
+func StreamNDJSON[T any](w io.Writer, items iter.Seq2[T, error]) error {
+	enc := json.NewEncoder(w)
+	for item, err := range items {
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(versioned{item}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func WritePlain(w io.Writer, lines []string) error {
@@ -2,6 +2,7 @@ package output
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -14,7 +15,12 @@ func TestWriteJSON(t *testing.T) {
 		{
 			name: "struct",
 			val:  struct{ Name string }{Name: "rift"},
-			want: "{\n  \"Name\": \"rift\"\n}\n",
+			want: "{\n  \"schema_version\": 1,\n  \"Name\": \"rift\"\n}\n",
+		},
+		{
+			name: "slice of structs",
+			val:  []struct{}{{}},
+			want: "[\n  {\n    \"schema_version\": 1\n  }\n]\n",
 		},
 		{
 			name: "slice with items",
@@ -76,3 +82,58 @@ func TestWritePlain(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	items := []struct {
+		Name string `json:"name"`
+	}{{"a"}, {"b"}}
+	if err := WriteNDJSON(&buf, items); err != nil {
+		t.Fatalf("WriteNDJSON() error = %v", err)
+	}
+	if got, want := buf.String(), "{\"schema_version\":1,\"name\":\"a\"}\n{\"schema_version\":1,\"name\":\"b\"}\n"; got != want {
+		t.Errorf("WriteNDJSON() = %q, want %q", got, want)
+	}
+}
+
+func TestStreamNDJSON_StopsAtError(t *testing.T) {
+	var buf bytes.Buffer
+	boom := errors.New("boom")
+	items := func(yield func(int, error) bool) {
+		if !yield(1, nil) {
+			return
+		}
+		if !yield(0, boom) {
+			return
+		}
+		yield(3, nil)
+	}
+	if err := StreamNDJSON(&buf, items); !errors.Is(err, boom) {
+		t.Fatalf("StreamNDJSON() error = %v, want boom", err)
+	}
+	if got := buf.String(); got != "1\n" {
+		t.Errorf("StreamNDJSON() wrote %q, want only the items before the error", got)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	e := Error{Code: "bad_ref", Message: `unknown revision "x"`, Ref: "x"}
+
+	var buf bytes.Buffer
+	if err := WriteError(&buf, NDJSON, e); err != nil {
+		t.Fatalf("WriteError() error = %v", err)
+	}
+	want := `{"schema_version":1,"error":{"code":"bad_ref","message":"unknown revision \"x\"","ref":"x"}}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteError(NDJSON) = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := WriteError(&buf, JSON, Error{Code: "error", Message: "boom"}); err != nil {
+		t.Fatalf("WriteError() error = %v", err)
+	}
+	want = "{\n  \"schema_version\": 1,\n  \"error\": {\n    \"code\": \"error\",\n    \"message\": \"boom\"\n  }\n}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteError(JSON) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is stamped as "schema_version" on every JSON object rift
+// writes. It goes up whenever a payload changes shape, so consumers can
+// pin the version they were written against.
+const SchemaVersion = 1
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// versioned marshals v with "schema_version" as its first field. Values
+// that aren't JSON objects are left as they are.
+type versioned struct{ v any }
+
+func (s versioned) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(s.v)
+	if err != nil || len(b) < 2 || b[0] != '{' {
+		return b, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`{"schema_version":`)
+	buf.WriteString(strconv.Itoa(SchemaVersion))
+	if body := b[1:]; body[0] != '}' {
+		buf.WriteByte(',')
+		buf.Write(body)
+	} else {
+		buf.WriteByte('}')
+	}
+	return buf.Bytes(), nil
+}
+
+// Stamp wraps v, or each element if v is a slice, so that the objects at
+// the top of the payload carry the schema version. Arrays stay arrays.
+func Stamp(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 || rv.IsNil() {
+		return versioned{v}
+	}
+	items := make([]versioned, rv.Len())
+	for i := range items {
+		items[i] = versioned{rv.Index(i).Interface()}
+	}
+	return items
+}
+
+// Schema returns a JSON Schema for what WriteJSON writes given a value of
+// v's type, derived from its struct fields and json tags the way
+// encoding/json reads them: omitempty fields are optional, the rest are
+// required, and objects allow no other properties. A field's doc tag, if
+// any, becomes its description. When v is a slice, its
+// items schema also describes each line WriteNDJSON writes.
+func Schema(title, description string, v any) map[string]any {
+	t := deref(reflect.TypeOf(v))
+	var s map[string]any
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		s = map[string]any{"type": "array", "items": withVersion(typeSchema(t.Elem()))}
+	} else {
+		s = withVersion(typeSchema(t))
+	}
+	s["$schema"] = schemaDialect
+	s["title"] = title
+	if description != "" {
+		s["description"] = description
+	}
+	return s
+}
+
+// TypeSchema returns the JSON Schema of v's type alone, without the
+// schema_version WriteJSON adds, e.g. to describe the params of a call.
+func TypeSchema(v any) map[string]any {
+	return typeSchema(reflect.TypeOf(v))
+}
+
+// withVersion adds the schema_version property Stamp writes to an object
+// schema.
+func withVersion(s map[string]any) map[string]any {
+	if s["type"] != "object" {
+		return s
+	}
+	props, _ := s["properties"].(map[string]any)
+	if props == nil {
+		props = map[string]any{}
+		s["properties"] = props
+	}
+	props["schema_version"] = map[string]any{"type": "integer", "const": SchemaVersion}
+	required, _ := s["required"].([]string)
+	s["required"] = append([]string{"schema_version"}, required...)
+	return s
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	t = deref(t)
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return objectSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+func objectSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && deref(f.Type).Kind() == reflect.Struct {
+				addFields(deref(f.Type))
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			s := typeSchema(f.Type)
+			if doc := f.Tag.Get("doc"); doc != "" {
+				s["description"] = doc
+			}
+			omitempty := strings.Contains(","+opts+",", ",omitempty,")
+			if f.Type.Kind() == reflect.Pointer && !omitempty {
+				s = map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+			}
+			props[name] = s
+			if !omitempty {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type schemaInner struct {
+	Line int `json:"line"`
+}
+
+type schemaBase struct {
+	Path string `json:"path"`
+}
+
+type schemaItem struct {
+	schemaBase
+	Status  string        `json:"status"`
+	Note    string        `json:"note,omitempty"`
+	Lines   []schemaInner `json:"lines"`
+	Err     *Error        `json:"error,omitempty"`
+	Skipped string        `json:"-"`
+	hidden  string
+}
+
+func TestSchema(t *testing.T) {
+	s := Schema("rift test --json", "", []schemaItem{})
+	if s["type"] != "array" || s["$schema"] != schemaDialect {
+		t.Fatalf("Schema() = %v, want an array schema", s)
+	}
+	items := s["items"].(map[string]any)
+	want := []string{"schema_version", "path", "status", "lines"}
+	if got := items["required"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("required = %v, want %v", got, want)
+	}
+	props := items["properties"].(map[string]any)
+	for _, name := range []string{"note", "error"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("optional property %q missing", name)
+		}
+	}
+	for _, name := range []string{"Skipped", "hidden", "schemaBase"} {
+		if _, ok := props[name]; ok {
+			t.Errorf("property %q should not be in the schema", name)
+		}
+	}
+	lines := props["lines"].(map[string]any)["items"].(map[string]any)
+	if _, ok := lines["properties"].(map[string]any)["schema_version"]; ok {
+		t.Error("nested objects aren't stamped, so shouldn't require schema_version")
+	}
+}
+
+// TestSchema_MatchesOutput checks that what WriteNDJSON writes has exactly
+// the properties the schema describes.
+func TestSchema_MatchesOutput(t *testing.T) {
+	var buf bytes.Buffer
+	item := schemaItem{schemaBase: schemaBase{Path: "a.go"}, Status: "M", Lines: []schemaInner{{1}}}
+	if err := WriteNDJSON(&buf, []schemaItem{item}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	props := Schema("", "", schemaItem{})["properties"].(map[string]any)
+	for name := range got {
+		if _, ok := props[name]; !ok {
+			t.Errorf("output has %q, which the schema doesn't describe", name)
+		}
+	}
+	if got["schema_version"] != float64(SchemaVersion) {
+		t.Errorf("schema_version = %v, want %d", got["schema_version"], SchemaVersion)
+	}
+}
@@ -0,0 +1,150 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/cobra"
+)
+
+// TemplateFormat returns the --format template, or "" if none was given.
+func TemplateFormat(cmd *cobra.Command) string {
+	f, _ := cmd.Flags().GetString("format")
+	return f
+}
+
+// WriteTemplate renders each item through format, one item per line, e.g.
+// `{{.Hash}} {{.Author | pad 20}} {{.Date | ago}}`. Fields are the Go field
+// names of the item type; see TemplateFuncs for the helpers.
+func WriteTemplate[T any](w io.Writer, format string, items []T) error {
+	tmpl, err := template.New("format").Funcs(TemplateFuncs(time.Now())).Parse(format)
+	if err != nil {
+		return fmt.Errorf("parse --format: %w", err)
+	}
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return fmt.Errorf("render --format: %w", err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TemplateFuncs returns the helpers available in --format templates, with
+// relative dates measured from now:
+//
+//	short     first 7 characters of a hash
+//	ago       "2025-01-15 10:30" as "3 days ago"
+//	pad N     left-align in N columns; padLeft N right-aligns
+//	trunc N   cut to N columns, ending in "…" if shortened
+//	color C   wrap in an ANSI color (red, green, yellow, blue, magenta,
+//	          cyan, white, gray, bold, dim); a no-op when NO_COLOR is set
+func TemplateFuncs(now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"short":   shortHash,
+		"ago":     func(date string) string { return relativeDate(date, now) },
+		"pad":     func(n int, s string) string { return pad(s, n, false) },
+		"padLeft": func(n int, s string) string { return pad(s, n, true) },
+		"trunc":   func(n int, s string) string { return ansi.Truncate(s, n, "…") },
+		"color":   colorize,
+	}
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func pad(s string, n int, left bool) string {
+	fill := n - ansi.StringWidth(s)
+	if fill <= 0 {
+		return s
+	}
+	if left {
+		return strings.Repeat(" ", fill) + s
+	}
+	return s + strings.Repeat(" ", fill)
+}
+
+// dateLayouts are the forms dates take in rift's items: log and branch
+// dates, and git's ISO-like %ci for stashes.
+var dateLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05 -0700",
+	time.RFC3339,
+}
+
+// relativeDate renders date relative to now the way git's --date=relative
+// does. Dates it can't parse come back unchanged.
+func relativeDate(date string, now time.Time) string {
+	var t time.Time
+	var err error
+	for _, layout := range dateLayouts {
+		if t, err = time.ParseInLocation(layout, date, time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return date
+	}
+
+	d := now.Sub(t)
+	if d < 0 {
+		return "in the future"
+	}
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", name)
+		}
+		return fmt.Sprintf("%d %ss ago", n, name)
+	}
+	switch {
+	case d < time.Minute:
+		return unit(int(d.Seconds()), "second")
+	case d < time.Hour:
+		return unit(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return unit(int(d.Hours()), "hour")
+	case d < 14*24*time.Hour:
+		return unit(int(d.Hours()/24), "day")
+	case d < 60*24*time.Hour:
+		return unit(int(d.Hours()/24/7), "week")
+	case d < 365*24*time.Hour:
+		return unit(int(d.Hours()/24/30), "month")
+	default:
+		return unit(int(d.Hours()/24/365), "year")
+	}
+}
+
+var ansiColors = map[string]string{
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+	"bold":    "1",
+	"dim":     "2",
+}
+
+func colorize(name, s string) (string, error) {
+	code, ok := ansiColors[name]
+	if !ok {
+		return "", fmt.Errorf("unknown color %q", name)
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return s, nil
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m", nil
+}
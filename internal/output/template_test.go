@@ -0,0 +1,109 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type templateItem struct {
+	Hash    string
+	Author  string
+	Date    string
+	Message string
+}
+
+func TestWriteTemplate(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	items := []templateItem{
+		{Hash: "abc1234def", Author: "Ann", Date: "2025-01-15 10:30", Message: "Add parser"},
+		{Hash: "0011223344", Author: "Bartholomew", Date: "2025-01-14 09:00", Message: "Initial commit"},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "fields",
+			format: "{{.Hash}} {{.Author}}",
+			want:   "abc1234def Ann\n0011223344 Bartholomew\n",
+		},
+		{
+			name:   "short and pad",
+			format: "{{.Hash | short}} {{.Author | pad 6}}|",
+			want:   "abc1234 Ann   |\n0011223 Bartholomew|\n",
+		},
+		{
+			name:   "padLeft and trunc",
+			format: "{{.Author | padLeft 4}} {{.Message | trunc 6}}",
+			want:   " Ann Add p…\nBartholomew Initi…\n",
+		},
+		{
+			name:   "color",
+			format: `{{.Author | color "green"}}`,
+			want:   "\x1b[32mAnn\x1b[0m\n\x1b[32mBartholomew\x1b[0m\n",
+		},
+		{
+			name:   "escapes in string literals",
+			format: `{{.Author}}{{"\t"}}{{.Hash | short}}`,
+			want:   "Ann\tabc1234\nBartholomew\t0011223\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteTemplate(&buf, tt.format, items); err != nil {
+				t.Fatalf("WriteTemplate() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_Errors(t *testing.T) {
+	items := []templateItem{{Hash: "abc"}}
+	for _, format := range []string{"{{.Hash", "{{.Missing}}", `{{.Hash | color "plaid"}}`} {
+		var buf bytes.Buffer
+		if err := WriteTemplate(&buf, format, items); err == nil {
+			t.Errorf("WriteTemplate(%q) succeeded, want an error", format)
+		}
+	}
+}
+
+func TestColor_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, `{{.Hash | color "red"}}`, []templateItem{{Hash: "abc"}}); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if got := buf.String(); got != "abc\n" {
+		t.Errorf("with NO_COLOR, got %q, want plain text", got)
+	}
+}
+
+func TestRelativeDate(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2025-03-01 11:59", "1 minute ago"},
+		{"2025-03-01 09:00", "3 hours ago"},
+		{"2025-02-26 12:00", "3 days ago"},
+		{"2025-02-01 12:00", "4 weeks ago"},
+		{"2024-11-01 12:00", "4 months ago"},
+		{"2023-01-01 12:00", "2 years ago"},
+		{"2025-03-02 12:00", "in the future"},
+		{"2025-02-28 12:00:00 " + now.Format("-0700"), "1 day ago"},
+		{"yesterday-ish", "yesterday-ish"},
+	}
+	for _, tt := range tests {
+		if got := relativeDate(tt.date, now); got != tt.want {
+			t.Errorf("relativeDate(%q) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
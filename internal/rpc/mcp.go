@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/madhermit/rift/internal/output"
+)
+
+// mcpVersions are the MCP protocol revisions the server speaks, newest
+// first. A client asking for another gets the newest.
+var mcpVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+type initializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type callParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content           []content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError,omitempty"`
+}
+
+// registerMCP adds the Model Context Protocol's lifecycle and tool methods.
+// Every registered method is also a tool of the same name, so MCP clients
+// and plain JSON-RPC clients reach the same handlers.
+func (s *Server) registerMCP() {
+	s.methods["initialize"] = Method{Handler: func(_ context.Context, raw json.RawMessage) (any, error) {
+		var p initializeParams
+		json.Unmarshal(raw, &p)
+		version := mcpVersions[0]
+		if slices.Contains(mcpVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": s.name, "version": s.version},
+		}, nil
+	}}
+	s.methods["notifications/initialized"] = Method{Handler: func(context.Context, json.RawMessage) (any, error) {
+		return nil, nil
+	}}
+	s.methods["ping"] = Method{Handler: func(context.Context, json.RawMessage) (any, error) {
+		return struct{}{}, nil
+	}}
+	s.methods["tools/list"] = Method{Handler: func(context.Context, json.RawMessage) (any, error) {
+		tools := make([]tool, len(s.tools))
+		for i, m := range s.tools {
+			schema := map[string]any{"type": "object", "properties": map[string]any{}}
+			if m.Params != nil {
+				schema = output.TypeSchema(m.Params)
+			}
+			tools[i] = tool{Name: m.Name, Description: m.Description, InputSchema: schema}
+		}
+		return map[string]any{"tools": tools}, nil
+	}}
+	s.methods["tools/call"] = Method{Handler: s.callTool}
+}
+
+// callTool runs a method as an MCP tool. A failing tool is still a
+// successful call, with the failure in the result for the model to read;
+// only an unknown tool or bad arguments are protocol errors.
+func (s *Server) callTool(ctx context.Context, raw json.RawMessage) (any, error) {
+	var p callParams
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, InvalidParams("invalid params: %v", err)
+	}
+	m, ok := s.methods[p.Name]
+	if !ok || !slices.ContainsFunc(s.tools, func(t Method) bool { return t.Name == p.Name }) {
+		return nil, InvalidParams("unknown tool %q", p.Name)
+	}
+
+	result, err := m.Handler(ctx, p.Arguments)
+	if err != nil {
+		e := asError(err)
+		if e.Code == CodeInvalidParams {
+			return nil, e
+		}
+		text := e.Message
+		if e.Data != nil {
+			if b, err := json.Marshal(e.Data); err == nil {
+				text = string(b)
+			}
+		}
+		return callResult{Content: []content{{Type: "text", Text: text}}, IsError: true}, nil
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s result: %w", p.Name, err)
+	}
+	return callResult{Content: []content{{Type: "text", Text: string(b)}}, StructuredContent: result}, nil
+}
@@ -0,0 +1,214 @@
+// Package rpc serves JSON-RPC 2.0 over a stream of newline-delimited
+// messages, with the Model Context Protocol's tool methods layered on the
+// same registered methods (see mcp.go).
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Standard JSON-RPC 2.0 error codes. CodeServerError is for failures of an
+// otherwise valid call; rift puts its own error object in Data.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeServerError    = -32000
+)
+
+// Error is a JSON-RPC error object. Handlers return one to pick the code;
+// any other error is reported as CodeInternalError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// InvalidParams reports a call whose params don't make sense.
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler answers a call. params is the raw "params" member, nil if the
+// call had none.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Method is a callable method, also listed as an MCP tool.
+type Method struct {
+	Name        string
+	Description string
+	// Params is a zero value of the params type, used for the tool's input
+	// schema; nil for methods that take none.
+	Params  any
+	Handler Handler
+}
+
+// NewMethod builds a Method whose params are decoded into P, strictly:
+// unknown fields are invalid params rather than silently ignored.
+func NewMethod[P, R any](name, description string, fn func(context.Context, P) (R, error)) Method {
+	var zero P
+	return Method{
+		Name:        name,
+		Description: description,
+		Params:      zero,
+		Handler: func(ctx context.Context, raw json.RawMessage) (any, error) {
+			var p P
+			if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+				dec := json.NewDecoder(bytes.NewReader(raw))
+				dec.DisallowUnknownFields()
+				if err := dec.Decode(&p); err != nil {
+					return nil, InvalidParams("invalid params: %v", err)
+				}
+			}
+			return fn(ctx, p)
+		},
+	}
+}
+
+// Server dispatches calls to registered methods. Serve handles calls one at
+// a time in the order they arrive, so handlers never race each other.
+type Server struct {
+	name    string
+	version string
+	methods map[string]Method
+	tools   []Method // registration order, for tools/list
+}
+
+func NewServer(name, version string) *Server {
+	s := &Server{name: name, version: version, methods: map[string]Method{}}
+	s.registerMCP()
+	return s
+}
+
+// Register adds m as a JSON-RPC method and an MCP tool.
+func (s *Server) Register(m Method) {
+	s.methods[m.Name] = m
+	s.tools = append(s.tools, m)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+var nullID = json.RawMessage("null")
+
+// Serve reads one message per line from r and writes each response on its
+// own line to w, until r ends or ctx is done.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if reply := s.handleMessage(ctx, line); reply != nil {
+			if err := enc.Encode(reply); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// handleMessage answers a single request or a batch. It returns nil when
+// there's nothing to send back: a notification, or a batch of them.
+func (s *Server) handleMessage(ctx context.Context, msg []byte) any {
+	if msg[0] != '[' {
+		if resp := s.handleRaw(ctx, msg); resp != nil {
+			return resp
+		}
+		return nil
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(msg, &batch); err != nil {
+		return errorResponse(nullID, &Error{Code: CodeParseError, Message: err.Error()})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nullID, &Error{Code: CodeInvalidRequest, Message: "empty batch"})
+	}
+	var replies []*response
+	for _, m := range batch {
+		if resp := s.handleRaw(ctx, m); resp != nil {
+			replies = append(replies, resp)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return replies
+}
+
+func (s *Server) handleRaw(ctx context.Context, msg []byte) *response {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return errorResponse(nullID, &Error{Code: CodeParseError, Message: err.Error()})
+		}
+		return errorResponse(nullID, &Error{Code: CodeInvalidRequest, Message: err.Error()})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = nullID
+		}
+		return errorResponse(id, &Error{Code: CodeInvalidRequest, Message: `not a JSON-RPC 2.0 request`})
+	}
+
+	result, err := s.call(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return nil // a notification: no reply, even on error
+	}
+	if err != nil {
+		return errorResponse(req.ID, asError(err))
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) call(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	m, ok := s.methods[method]
+	if !ok {
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", method)}
+	}
+	return m.Handler(ctx, params)
+}
+
+func asError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
+
+func errorResponse(id json.RawMessage, e *Error) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: e}
+}
@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type echoParams struct {
+	Text string `json:"text"`
+}
+
+func newTestServer() *Server {
+	s := NewServer("rift", "test")
+	s.Register(NewMethod("echo", "Echo text back.", func(_ context.Context, p echoParams) (any, error) {
+		if p.Text == "fail" {
+			return nil, &Error{Code: CodeServerError, Message: "failed", Data: map[string]string{"code": "error"}}
+		}
+		if p.Text == "crash" {
+			return nil, errors.New("boom")
+		}
+		return map[string]string{"text": p.Text}, nil
+	}))
+	return s
+}
+
+// serve runs the server over input and returns its output lines.
+func serve(t *testing.T, s *Server, input ...string) []string {
+	t.Helper()
+	var out strings.Builder
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(input, "\n")), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+}
+
+func TestServe(t *testing.T) {
+	got := serve(t, newTestServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`,
+		`{"jsonrpc":"2.0","method":"echo","params":{"text":"notification"}}`,
+		``,
+		`{"jsonrpc":"2.0","id":"a","method":"echo","params":{"text":"fail"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"echo","params":{"text":"crash"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"echo","params":{"txt":"typo"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"missing"}`,
+		`{"id":5,"method":"echo"}`,
+		`{nope`,
+		`[{"jsonrpc":"2.0","id":6,"method":"echo","params":{"text":"x"}},{"jsonrpc":"2.0","method":"echo"}]`,
+		`[]`,
+	)
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"text":"hi"}}`,
+		`{"jsonrpc":"2.0","id":"a","error":{"code":-32000,"message":"failed","data":{"code":"error"}}}`,
+		`{"jsonrpc":"2.0","id":2,"error":{"code":-32603,"message":"boom"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"invalid params: json: unknown field \"txt\""}}`,
+		`{"jsonrpc":"2.0","id":4,"error":{"code":-32601,"message":"method \"missing\" not found"}}`,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":-32600,"message":"not a JSON-RPC 2.0 request"}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"invalid character 'n' looking for beginning of object key string"}}`,
+		`[{"jsonrpc":"2.0","id":6,"result":{"text":"x"}}]`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"empty batch"}}`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Serve() wrote %d lines, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d =\n%s\nwant:\n%s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMCP(t *testing.T) {
+	got := serve(t, newTestServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{"text":"fail"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"initialize"}}`,
+	)
+	if len(got) != 5 {
+		t.Fatalf("Serve() wrote %d lines, want 5:\n%s", len(got), strings.Join(got, "\n"))
+	}
+
+	var init struct {
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+	}
+	json.Unmarshal([]byte(got[0]), &init)
+	if init.Result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("initialize = %s, want the client's protocol version echoed", got[0])
+	}
+
+	var list struct {
+		Result struct {
+			Tools []tool `json:"tools"`
+		} `json:"result"`
+	}
+	json.Unmarshal([]byte(got[1]), &list)
+	if len(list.Result.Tools) != 1 || list.Result.Tools[0].Name != "echo" {
+		t.Fatalf("tools/list = %s, want only echo", got[1])
+	}
+	if props := list.Result.Tools[0].InputSchema["properties"].(map[string]any); props["text"] == nil {
+		t.Errorf("echo's input schema = %v, want a text property", list.Result.Tools[0].InputSchema)
+	}
+
+	if want := `{"jsonrpc":"2.0","id":3,"result":{"content":[{"type":"text","text":"{\"text\":\"hi\"}"}],"structuredContent":{"text":"hi"}}}`; got[2] != want {
+		t.Errorf("tools/call =\n%s\nwant:\n%s", got[2], want)
+	}
+	if want := `{"jsonrpc":"2.0","id":4,"result":{"content":[{"type":"text","text":"{\"code\":\"error\"}"}],"isError":true}}`; got[3] != want {
+		t.Errorf("failing tools/call =\n%s\nwant:\n%s", got[3], want)
+	}
+	if !strings.Contains(got[4], `"code":-32602`) {
+		t.Errorf("calling a protocol method as a tool = %s, want invalid params", got[4])
+	}
+}
@@ -0,0 +1,440 @@
+package commitui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/tui"
+	diffui "github.com/madhermit/rift/internal/tui/diff"
+)
+
+// Subject lines should fit in 50 columns and must not exceed 72, which is
+// also the wrap width for the body.
+const (
+	subjectSoftLimit = 50
+	subjectHardLimit = 72
+)
+
+type pane int
+
+const (
+	editorPane pane = iota
+	filePane
+	diffPane
+)
+
+type severity int
+
+const (
+	guideOK severity = iota
+	guideWarn
+	guideErr
+)
+
+type Model struct {
+	repo   *git.Repo
+	engine diff.Engine
+
+	files       []git.ChangedFile // staged files, "All" entry first
+	selectedIdx int
+	activePane  pane
+
+	editor   textarea.Model
+	viewport viewport.Model
+	vim      tui.VimNav
+	render   *tui.Render
+
+	diffContent string
+	statusMsg   string
+
+	amend       bool
+	headMessage string // HEAD's message, prefilled when amending
+	message     string
+	committed   bool
+
+	width  int
+	height int
+	ready  bool
+}
+
+type diffLoadedMsg struct {
+	content string
+}
+
+type layout struct {
+	headerHeight  int
+	contentHeight int
+	leftWidth     int
+	editorHeight  int
+	listHeight    int
+	diffWidth     int
+}
+
+func (m Model) layout() layout {
+	l := layout{headerHeight: 3}
+	l.contentHeight = m.height - l.headerHeight
+
+	// Wide enough for a 72-column body plus borders when there's room.
+	l.leftWidth = min(max(m.width/2, 30), subjectHardLimit+4)
+	l.editorHeight = min(max(l.contentHeight/2, 5), 16)
+	// editor and list borders plus the guidance line
+	l.listHeight = max(l.contentHeight-l.editorHeight-5, 1)
+	l.diffWidth = max(m.width-l.leftWidth-2, 10)
+	return l
+}
+
+// New builds the commit composer for the staged files. message prefills the
+// editor; when amend is set and message is empty, headMessage is used.
+func New(repo *git.Repo, engine diff.Engine, files []git.ChangedFile, message string, amend bool, headMessage string) Model {
+	editor := textarea.New()
+	editor.Placeholder = "Subject line\n\nWhy this change is needed..."
+	editor.ShowLineNumbers = false
+	editor.Prompt = ""
+	editor.CharLimit = 0
+	if message == "" && amend {
+		message = headMessage
+	}
+	editor.SetValue(message)
+	editor.Focus()
+
+	all := make([]git.ChangedFile, 0, len(files)+1)
+	all = append(all, git.ChangedFile{Path: "", Status: "All"})
+	all = append(all, files...)
+
+	return Model{
+		repo:        repo,
+		engine:      engine,
+		files:       all,
+		editor:      editor,
+		viewport:    viewport.New(0, 0),
+		amend:       amend,
+		headMessage: headMessage,
+		render:      &tui.Render{},
+	}
+}
+
+// Committed reports whether the user confirmed the commit.
+func (m Model) Committed() bool { return m.committed }
+
+// Message returns the confirmed commit message.
+func (m Model) Message() string { return m.message }
+
+// Amend reports whether the commit should amend HEAD.
+func (m Model) Amend() bool { return m.amend }
+
+func (m Model) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tea.WindowSizeMsg:
+		first := !m.ready
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		m.applyLayout()
+		if first {
+			return m, m.loadSelectedDiff()
+		}
+		m.setDiffContent()
+		return m, nil
+	case diffLoadedMsg:
+		m.diffContent = msg.content
+		m.setDiffContent()
+		m.viewport.GotoTop()
+		return m, nil
+	}
+
+	if m.activePane == editorPane {
+		var cmd tea.Cmd
+		m.editor, cmd = m.editor.Update(msg)
+		return m, cmd
+	}
+	if m.activePane == diffPane {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyCtrlS:
+		return m.commit()
+	case tea.KeyTab:
+		return m.focus((m.activePane + 1) % 3)
+	case tea.KeyShiftTab:
+		return m.focus((m.activePane + 2) % 3)
+	}
+
+	if m.activePane == editorPane {
+		if msg.Type == tea.KeyEsc {
+			return m.focus(filePane)
+		}
+		var cmd tea.Cmd
+		m.editor, cmd = m.editor.Update(msg)
+		m.statusMsg = ""
+		return m, cmd
+	}
+
+	if m.activePane == diffPane && m.vim.HandleKey(&m.viewport, msg) {
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		if m.activePane == filePane {
+			return m.focus(diffPane)
+		}
+	case tea.KeyUp:
+		if m.activePane == filePane {
+			return m.moveSelection(-1)
+		}
+	case tea.KeyDown:
+		if m.activePane == filePane {
+			return m.moveSelection(1)
+		}
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "q":
+			return m, tea.Quit
+		case "i", "e":
+			return m.focus(editorPane)
+		case "a":
+			return m.toggleAmend()
+		case "j":
+			if m.activePane == filePane {
+				return m.moveSelection(1)
+			}
+		case "k":
+			if m.activePane == filePane {
+				return m.moveSelection(-1)
+			}
+		}
+	}
+
+	if m.activePane == diffPane {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) focus(p pane) (tea.Model, tea.Cmd) {
+	m.activePane = p
+	if p == editorPane {
+		return m, m.editor.Focus()
+	}
+	m.editor.Blur()
+	return m, nil
+}
+
+// toggleAmend switches between a new commit and amending HEAD, swapping in
+// HEAD's message when the editor holds nothing of the user's own.
+func (m Model) toggleAmend() (tea.Model, tea.Cmd) {
+	m.amend = !m.amend
+	value := strings.TrimSpace(m.editor.Value())
+	switch {
+	case m.amend && value == "":
+		m.editor.SetValue(m.headMessage)
+	case !m.amend && value == strings.TrimSpace(m.headMessage):
+		m.editor.Reset()
+	}
+	return m, nil
+}
+
+func (m Model) commit() (tea.Model, tea.Cmd) {
+	message := strings.TrimSpace(m.editor.Value())
+	if message == "" {
+		m.statusMsg = "Commit message is empty"
+		return m, nil
+	}
+	if len(m.files) <= 1 && !m.amend {
+		m.statusMsg = "Nothing staged to commit"
+		return m, nil
+	}
+	m.message = message
+	m.committed = true
+	return m, tea.Quit
+}
+
+func (m *Model) applyLayout() {
+	l := m.layout()
+	m.editor.SetWidth(l.leftWidth - 2)
+	m.editor.SetHeight(l.editorHeight)
+	m.viewport.Width = l.diffWidth
+	m.viewport.Height = l.contentHeight - 2
+}
+
+func (m Model) moveSelection(delta int) (tea.Model, tea.Cmd) {
+	idx := m.selectedIdx + delta
+	if idx < 0 || idx >= len(m.files) {
+		return m, nil
+	}
+	m.selectedIdx = idx
+	return m, m.loadSelectedDiff()
+}
+
+func (m Model) loadSelectedDiff() tea.Cmd {
+	files := m.files[1:]
+	if m.selectedIdx > 0 {
+		files = m.files[m.selectedIdx : m.selectedIdx+1]
+	}
+	engine := m.engine
+	repoRoot := m.repo.Root()
+	width := m.viewport.Width
+	return m.render.Start(func(ctx context.Context) tea.Msg {
+		opts := diff.DiffOpts{
+			Staged: true,
+			Color:  os.Getenv("NO_COLOR") == "",
+			Width:  width,
+		}
+		return diffLoadedMsg{content: diffui.RenderFiles(ctx, engine, repoRoot, files, opts)}
+	})
+}
+
+func (m *Model) setDiffContent() {
+	content := m.diffContent
+	if w := m.viewport.Width; w > 0 && content != "" {
+		content = ansi.Hardwrap(content, w, true)
+	}
+	m.vim.SetContent(&m.viewport, content)
+}
+
+// checkMessage measures msg against the usual conventions: a subject of at
+// most 50 (and never over 72) columns, a blank second line, and a body
+// wrapped at 72.
+func checkMessage(msg string) (subjectLen int, level severity, note string) {
+	lines := strings.Split(msg, "\n")
+	subjectLen = utf8.RuneCountInString(strings.TrimRight(lines[0], " \t"))
+
+	if subjectLen == 0 {
+		return 0, guideWarn, "empty subject"
+	}
+	if subjectLen > subjectHardLimit {
+		return subjectLen, guideErr, fmt.Sprintf("subject over %d chars", subjectHardLimit)
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		return subjectLen, guideWarn, "leave line 2 blank"
+	}
+	for i, line := range lines[min(2, len(lines)):] {
+		if utf8.RuneCountInString(line) > subjectHardLimit {
+			return subjectLen, guideWarn, fmt.Sprintf("line %d over %d chars", i+3, subjectHardLimit)
+		}
+	}
+	if subjectLen > subjectSoftLimit {
+		return subjectLen, guideWarn, fmt.Sprintf("subject over %d chars", subjectSoftLimit)
+	}
+	return subjectLen, guideOK, ""
+}
+
+func (m Model) renderGuidance() string {
+	n, level, note := checkMessage(m.editor.Value())
+	text := fmt.Sprintf("subject %d/%d", n, subjectSoftLimit)
+	if note != "" {
+		text += "  " + note
+	}
+	switch level {
+	case guideErr:
+		return guideErrStyle.Render(text)
+	case guideWarn:
+		return guideWarnStyle.Render(text)
+	default:
+		return guideOKStyle.Render(text)
+	}
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	l := m.layout()
+
+	titleText := fmt.Sprintf("rift commit  [%s]", m.engine.Name())
+	title := titleStyle.Render(titleText)
+	if m.amend {
+		title += "  " + amendStyle.Render("amend")
+	}
+
+	editorStyle, listStyle, vpStyle := paneStyle, paneStyle, paneStyle
+	switch m.activePane {
+	case editorPane:
+		editorStyle = activePaneStyle
+	case filePane:
+		listStyle = activePaneStyle
+	case diffPane:
+		vpStyle = activePaneStyle
+	}
+
+	// File list with scroll
+	var fileList strings.Builder
+	scrollOffset := 0
+	if m.selectedIdx >= l.listHeight {
+		scrollOffset = m.selectedIdx - l.listHeight + 1
+	}
+	for i := scrollOffset; i < len(m.files) && i-scrollOffset < l.listHeight; i++ {
+		f := m.files[i]
+		var line string
+		if f.Path == "" {
+			line = fmt.Sprintf("* All (%d staged)", len(m.files)-1)
+		} else {
+			line = diffui.StatusIcon(f.Status) + " " + tui.FileIcon(f.Path) + " " + truncate(diffui.DisplayPath(f), l.leftWidth-8)
+		}
+		if i == m.selectedIdx {
+			fileList.WriteString(selectedFileStyle.Render(line))
+		} else {
+			fileList.WriteString(fileItemStyle.Render(line))
+		}
+		fileList.WriteString("\n")
+	}
+
+	editorView := editorStyle.Width(l.leftWidth - 2).Height(l.editorHeight).Render(m.editor.View())
+	listView := listStyle.Width(l.leftWidth - 2).Height(l.listHeight).Render(fileList.String())
+	left := lipgloss.JoinVertical(lipgloss.Left, editorView, m.renderGuidance(), listView)
+	diffView := vpStyle.Width(l.diffWidth).Height(l.contentHeight - 2).Render(m.viewport.View())
+
+	content := lipgloss.JoinHorizontal(lipgloss.Top, left, diffView)
+
+	// Status bar
+	var status string
+	switch {
+	case m.statusMsg != "":
+		status = statusBarStyle.Render(m.statusMsg)
+	case m.activePane == editorPane:
+		status = statusBarStyle.Render("ctrl+s:commit esc:leave editor tab:switch ctrl+c:abort")
+	default:
+		status = statusBarStyle.Render("ctrl+s:commit q:abort tab:switch i:edit a:amend j/k:nav gg/G:top/bot")
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, content, status)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	if max <= 3 {
+		return s[:max]
+	}
+	return "..." + s[len(s)-max+3:]
+}
@@ -0,0 +1,34 @@
+package commitui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       string
+		wantLen   int
+		wantLevel severity
+		wantNote  string
+	}{
+		{"empty", "", 0, guideWarn, "empty subject"},
+		{"short subject", "Fix typo", 8, guideOK, ""},
+		{"subject with body", "Fix typo\n\nIt was wrong.", 8, guideOK, ""},
+		{"long subject", strings.Repeat("x", 60), 60, guideWarn, "subject over 50 chars"},
+		{"too long subject", strings.Repeat("x", 80), 80, guideErr, "subject over 72 chars"},
+		{"no blank line", "Fix typo\nbody", 8, guideWarn, "leave line 2 blank"},
+		{"long body line", "Fix typo\n\nok\n" + strings.Repeat("y", 73), 8, guideWarn, "line 4 over 72 chars"},
+		{"multibyte subject", "Fix naïve café", 14, guideOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, level, note := checkMessage(tt.msg)
+			if n != tt.wantLen || level != tt.wantLevel || note != tt.wantNote {
+				t.Errorf("checkMessage() = (%d, %d, %q), want (%d, %d, %q)", n, level, note, tt.wantLen, tt.wantLevel, tt.wantNote)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package commitui
+
+import "github.com/charmbracelet/lipgloss"
+
+var (
+	subtle = lipgloss.Color("241")
+	accent = lipgloss.Color("39")
+	white  = lipgloss.Color("15")
+	green  = lipgloss.Color("2")
+	yellow = lipgloss.Color("3")
+	red    = lipgloss.Color("1")
+
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(accent).
+			PaddingLeft(1)
+
+	amendStyle = lipgloss.NewStyle().
+			Foreground(yellow).
+			Bold(true)
+
+	statusBarStyle = lipgloss.NewStyle().
+			Foreground(subtle).
+			PaddingLeft(1)
+
+	fileItemStyle = lipgloss.NewStyle().
+			Foreground(subtle).
+			PaddingLeft(1)
+
+	selectedFileStyle = lipgloss.NewStyle().
+				Foreground(white).
+				Background(lipgloss.Color("236")).
+				PaddingLeft(1)
+
+	paneStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(subtle)
+
+	activePaneStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(accent)
+
+	guideOKStyle = lipgloss.NewStyle().
+			Foreground(green).
+			PaddingLeft(1)
+
+	guideWarnStyle = lipgloss.NewStyle().
+			Foreground(yellow).
+			PaddingLeft(1)
+
+	guideErrStyle = lipgloss.NewStyle().
+			Foreground(red).
+			PaddingLeft(1)
+)
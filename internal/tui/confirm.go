@@ -0,0 +1,38 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Confirm holds a pending y/n prompt and the action to run if the user
+// answers yes. Embed in any TUI model that has destructive actions.
+type Confirm struct {
+	prompt string
+	action tea.Cmd
+}
+
+// Ask shows prompt and defers action until the user confirms.
+func (c *Confirm) Ask(prompt string, action tea.Cmd) {
+	c.prompt = prompt
+	c.action = action
+}
+
+// Active reports whether a prompt is waiting for an answer.
+func (c Confirm) Active() bool {
+	return c.action != nil
+}
+
+// Prompt returns the question shown to the user, with the key hint.
+func (c Confirm) Prompt() string {
+	return c.prompt + " [y/N]"
+}
+
+// HandleKey resolves the prompt. It returns the pending action on "y" and
+// nil on any other key; either way the prompt is cleared.
+func (c *Confirm) HandleKey(msg tea.KeyMsg) tea.Cmd {
+	action := c.action
+	c.prompt = ""
+	c.action = nil
+	if msg.Type == tea.KeyRunes && (string(msg.Runes) == "y" || string(msg.Runes) == "Y") {
+		return action
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfirm_HandleKey(t *testing.T) {
+	action := func() tea.Msg { return "done" }
+	runeMsg := func(r string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(r)}
+	}
+
+	tests := []struct {
+		name    string
+		key     tea.KeyMsg
+		wantRun bool
+	}{
+		{"y confirms", runeMsg("y"), true},
+		{"Y confirms", runeMsg("Y"), true},
+		{"n cancels", runeMsg("n"), false},
+		{"esc cancels", tea.KeyMsg{Type: tea.KeyEsc}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Confirm
+			c.Ask("Discard?", action)
+			if !c.Active() {
+				t.Fatal("expected prompt to be active after Ask")
+			}
+			got := c.HandleKey(tt.key)
+			if (got != nil) != tt.wantRun {
+				t.Errorf("HandleKey() returned action = %v, want %v", got != nil, tt.wantRun)
+			}
+			if c.Active() {
+				t.Error("expected prompt to be cleared after HandleKey")
+			}
+		})
+	}
+}
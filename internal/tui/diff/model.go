@@ -41,10 +41,12 @@ type Model struct {
 	diffContent string
 	diffErr     error
 	vim         tui.VimNav
+	render      *tui.Render
+	confirm     tui.Confirm
 
 	staged     bool
-	base       string
-	target     string
+	untracked  bool
+	rng        git.DiffRange
 	commitDiff bool
 
 	width  int
@@ -99,7 +101,7 @@ func prependAllEntry(files []git.ChangedFile) []git.ChangedFile {
 	return append(all, files...)
 }
 
-func New(repo *git.Repo, engine diff.Engine, files []git.ChangedFile, staged bool, base, target string) Model {
+func New(repo *git.Repo, engine diff.Engine, files []git.ChangedFile, staged, untracked bool, rng git.DiffRange) Model {
 	filter := textinput.New()
 	filter.Prompt = "/ "
 	filter.PromptStyle = filterPromptStyle
@@ -115,9 +117,10 @@ func New(repo *git.Repo, engine diff.Engine, files []git.ChangedFile, staged boo
 		viewport:      viewport.New(0, 0),
 		filter:        filter,
 		staged:        staged,
-		base:          base,
-		target:        target,
-		commitDiff:    target != "",
+		untracked:     untracked,
+		rng:           rng,
+		commitDiff:    rng.Target != "",
+		render:        &tui.Render{},
 	}
 }
 
@@ -171,6 +174,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirm.Active() {
+		return m, m.confirm.HandleKey(msg)
+	}
 	if m.activePane == diffPane && !m.filtering && m.vim.HandleKey(&m.viewport, msg) {
 		return m, nil
 	}
@@ -226,6 +232,10 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if !m.commitDiff {
 				return m.toggleStaged()
 			}
+		case "d":
+			if !m.commitDiff && !m.staged {
+				return m.discardFile()
+			}
 		}
 	}
 
@@ -263,10 +273,35 @@ func (m Model) navigate(delta int) (tea.Model, tea.Cmd) {
 
 func (m Model) toggleStaged() (tea.Model, tea.Cmd) {
 	m.staged = !m.staged
+	return m, m.reloadFiles()
+}
+
+// discardFile asks before throwing away the worktree changes to the selected
+// file. The "All" entry is never discarded wholesale.
+func (m Model) discardFile() (tea.Model, tea.Cmd) {
+	if len(m.filteredFiles) == 0 {
+		return m, nil
+	}
+	f := m.filteredFiles[m.selectedIdx]
+	if f.Path == "" {
+		return m, nil
+	}
+	repo := m.repo
+	reload := m.reloadFiles()
+	m.confirm.Ask(fmt.Sprintf("Discard changes to %s?", f.Path), func() tea.Msg {
+		if err := repo.DiscardFile(f.Path); err != nil {
+			return filesLoadedMsg{err: err}
+		}
+		return reload()
+	})
+	return m, nil
+}
+
+func (m Model) reloadFiles() tea.Cmd {
 	repo := m.repo
-	staged := m.staged
-	return m, func() tea.Msg {
-		files, err := repo.ChangedFiles(staged)
+	staged, untracked := m.staged, m.untracked
+	return func() tea.Msg {
+		files, err := repo.ChangedFiles(staged, untracked)
 		if err != nil {
 			return filesLoadedMsg{err: err}
 		}
@@ -337,41 +372,54 @@ func (m Model) moveSelection(delta int) (tea.Model, tea.Cmd) {
 func (m Model) loadSelectedDiff() tea.Cmd {
 	selected := m.filteredFiles[m.selectedIdx]
 	if selected.Path == "" {
-		var files []string
+		var files []git.ChangedFile
 		for _, f := range m.filteredFiles {
 			if f.Path != "" {
-				files = append(files, f.Path)
+				files = append(files, f)
 			}
 		}
 		return m.loadDiff(files...)
 	}
-	return m.loadDiff(selected.Path)
+	return m.loadDiff(selected)
 }
 
-func (m Model) loadDiff(files ...string) tea.Cmd {
+func (m Model) loadDiff(files ...git.ChangedFile) tea.Cmd {
 	width := m.viewport.Width
-	return func() tea.Msg {
-		color := os.Getenv("NO_COLOR") == ""
+	return m.render.Start(func(ctx context.Context) tea.Msg {
 		opts := diff.DiffOpts{
 			Staged: m.staged,
-			Base:   m.base,
-			Target: m.target,
-			Color:  color,
+			Base:   m.rng.From(),
+			Target: m.rng.Target,
+			Color:  os.Getenv("NO_COLOR") == "",
 			Width:  width,
 		}
-		var result strings.Builder
-		for _, file := range files {
-			content, err := m.engine.Diff(context.Background(), m.repo.Root(), file, opts)
-			if err != nil {
-				continue
-			}
-			if content != "" {
-				result.WriteString(content)
-				result.WriteString("\n")
-			}
+		return diffLoadedMsg{content: RenderFiles(ctx, m.engine, m.repo.Root(), files, opts)}
+	})
+}
+
+// RenderFiles runs the engine over each file, several at a time, and
+// concatenates the output in file order. Files that fail to diff are
+// skipped.
+func RenderFiles(ctx context.Context, engine diff.Engine, repoRoot string, files []git.ChangedFile, opts diff.DiffOpts) string {
+	contents := diff.RenderAll(ctx, len(files), func(ctx context.Context, i int) string {
+		opts := opts
+		opts.OldPath = files[i].OldPath
+		opts.Untracked = files[i].Untracked
+		content, err := engine.Diff(ctx, repoRoot, files[i].Path, opts)
+		if err != nil {
+			return ""
+		}
+		return content
+	})
+
+	var result strings.Builder
+	for _, content := range contents {
+		if content != "" {
+			result.WriteString(content)
+			result.WriteString("\n")
 		}
-		return diffLoadedMsg{content: result.String()}
 	}
+	return result.String()
 }
 
 func (m *Model) setDiffContent() {
@@ -390,7 +438,9 @@ func (m Model) View() string {
 	l := m.layout()
 
 	titleText := fmt.Sprintf("rift diff  [%s]", m.engine.Name())
-	if !m.commitDiff {
+	if m.commitDiff {
+		titleText += "  " + m.rng.String()
+	} else {
 		label := "unstaged"
 		if m.staged {
 			label = "staged"
@@ -417,9 +467,9 @@ func (m Model) View() string {
 				line = fmt.Sprintf("* All (%d files)", len(m.filteredFiles)-1)
 			}
 		} else if collapsed {
-			line = statusIcon(f.Status) + " " + tui.FileIcon(f.Path)
+			line = StatusIcon(f.Status) + " " + tui.FileIcon(f.Path)
 		} else {
-			line = statusIcon(f.Status) + " " + tui.FileIcon(f.Path) + " " + truncate(f.Path, l.listWidth-8)
+			line = StatusIcon(f.Status) + " " + tui.FileIcon(f.Path) + " " + truncate(DisplayPath(f), l.listWidth-8)
 		}
 		if i == m.selectedIdx {
 			fileList.WriteString(selectedFileStyle.Render(line))
@@ -446,11 +496,13 @@ func (m Model) View() string {
 	switch {
 	case m.filtering:
 		status = m.filter.View()
+	case m.confirm.Active():
+		status = confirmStyle.Render(m.confirm.Prompt())
 	case m.diffErr != nil:
 		status = statusBarStyle.Render(fmt.Sprintf("Error: %v", m.diffErr))
 	case len(m.filteredFiles) > 0:
 		f := m.filteredFiles[m.selectedIdx]
-		label := f.Path
+		label := DisplayPath(f)
 		if label == "" {
 			label = "All"
 		}
@@ -458,6 +510,9 @@ func (m Model) View() string {
 		keys := "q:quit /filter tab:switch j/k:nav gg/G:top/bot {/}:section"
 		if !m.commitDiff {
 			keys += " s:staged/unstaged"
+			if !m.staged {
+				keys += " d:discard"
+			}
 		}
 		status = statusBarStyle.Render(fmt.Sprintf("%s  %.0f%%  [%d/%d]  %s", label, pct, m.selectedIdx+1, len(m.filteredFiles), keys))
 	default:
@@ -477,7 +532,16 @@ func truncate(s string, max int) string {
 	return "..." + s[len(s)-max+3:]
 }
 
-func statusIcon(status string) string {
+// DisplayPath shows renames and copies as "old → new".
+func DisplayPath(f git.ChangedFile) string {
+	if f.OldPath != "" {
+		return f.OldPath + " → " + f.Path
+	}
+	return f.Path
+}
+
+// StatusIcon maps a ChangedFile status to its one-letter marker.
+func StatusIcon(status string) string {
 	switch status {
 	case "Modified":
 		return "M"
@@ -487,6 +551,8 @@ func statusIcon(status string) string {
 		return "D"
 	case "Renamed":
 		return "R"
+	case "Copied":
+		return "C"
 	case "Untracked":
 		return "?"
 	default:
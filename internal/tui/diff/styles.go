@@ -24,6 +24,11 @@ var (
 				Foreground(white).
 				PaddingLeft(2)
 
+	confirmStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("1")).
+			Bold(true).
+			PaddingLeft(1)
+
 	filterPromptStyle = lipgloss.NewStyle().
 				Foreground(accent).
 				Bold(true)
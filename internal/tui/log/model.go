@@ -40,6 +40,11 @@ type Model struct {
 	diffContent string
 	diffErr     error
 	vim         tui.VimNav
+	render      *tui.Render
+
+	title   string // command shown in the title bar
+	picking bool   // enter picks the selected commit and quits
+	picked  *git.CommitInfo
 
 	width  int
 	height int
@@ -96,7 +101,26 @@ func New(repo *git.Repo, engine diff.Engine, commits []git.CommitInfo) Model {
 		filteredCommits: commits,
 		viewport:        viewport.New(0, 0),
 		filter:          filter,
+		title:           "rift log",
+		render:          &tui.Render{},
+	}
+}
+
+// NewPicker is the log browser used to choose a commit: enter picks the
+// selected commit and quits. title names the command in the title bar.
+func NewPicker(repo *git.Repo, engine diff.Engine, commits []git.CommitInfo, title string) Model {
+	m := New(repo, engine, commits)
+	m.title = title
+	m.picking = true
+	return m
+}
+
+// Picked returns the commit chosen in a picker, if any.
+func (m Model) Picked() (git.CommitInfo, bool) {
+	if m.picked == nil {
+		return git.CommitInfo{}, false
 	}
+	return *m.picked, true
 }
 
 func (m Model) Init() tea.Cmd {
@@ -166,6 +190,11 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m.applyLayout()
 	case tea.KeyEnter:
+		if m.picking && len(m.filteredCommits) > 0 {
+			picked := m.filteredCommits[m.selectedIdx]
+			m.picked = &picked
+			return m, tea.Quit
+		}
 		if m.activePane == commitPane {
 			m.activePane = diffPane
 			return m.applyLayout()
@@ -321,7 +350,11 @@ func commitHeader(commit git.CommitInfo, files []git.ChangedFile, color bool, wi
 			if color {
 				icon = statusStyle(f.Status).Render(icon)
 			}
-			fmt.Fprintf(&b, "  %s %s %s\n", icon, tui.FileIcon(f.Path), f.Path)
+			path := f.Path
+			if f.OldPath != "" {
+				path = f.OldPath + " → " + f.Path
+			}
+			fmt.Fprintf(&b, "  %s %s %s\n", icon, tui.FileIcon(f.Path), path)
 		}
 	}
 
@@ -339,6 +372,8 @@ func statusIcon(status string) string {
 		return "D"
 	case "Renamed":
 		return "R"
+	case "Copied":
+		return "C"
 	default:
 		return " "
 	}
@@ -352,7 +387,7 @@ func statusStyle(status string) lipgloss.Style {
 		return statusDeletedStyle
 	case "Modified":
 		return statusModifiedStyle
-	case "Renamed":
+	case "Renamed", "Copied":
 		return statusRenamedStyle
 	default:
 		return lipgloss.NewStyle()
@@ -381,7 +416,7 @@ func reflowParagraphs(s string) string {
 
 func (m Model) loadCommitDiff(commit git.CommitInfo) tea.Cmd {
 	width := m.viewport.Width
-	return func() tea.Msg {
+	return m.render.Start(func(ctx context.Context) tea.Msg {
 		// Fetch changed file list for the header
 		base := commit.Hash + "~1"
 		files, err := m.repo.DiffBetweenCommits(base, commit.Hash)
@@ -392,13 +427,13 @@ func (m Model) loadCommitDiff(commit git.CommitInfo) tea.Cmd {
 		color := os.Getenv("NO_COLOR") == ""
 		header := commitHeader(commit, files, color, width)
 		content, err := m.engine.DiffCommit(
-			context.Background(), m.repo.Root(),
+			ctx, m.repo.Root(),
 			base, commit.Hash, color, width,
 		)
 		if err != nil {
 			// First commit has no parent — diff against empty tree
 			content, err = m.engine.DiffCommit(
-				context.Background(), m.repo.Root(),
+				ctx, m.repo.Root(),
 				"4b825dc642cb6eb9a060e54bf899d69f82cf7207", commit.Hash, color, width,
 			)
 		}
@@ -406,7 +441,7 @@ func (m Model) loadCommitDiff(commit git.CommitInfo) tea.Cmd {
 			return diffLoadedMsg{content: content, err: err}
 		}
 		return diffLoadedMsg{content: header + content}
-	}
+	})
 }
 
 func (m *Model) setDiffContent() {
@@ -424,7 +459,7 @@ func (m Model) View() string {
 
 	l := m.layout()
 
-	title := titleStyle.Render(fmt.Sprintf("rift log  [%s]", m.engine.Name()))
+	title := titleStyle.Render(fmt.Sprintf("%s  [%s]", m.title, m.engine.Name()))
 
 	// Commit list with scroll
 	var commitList strings.Builder
@@ -472,9 +507,13 @@ func (m Model) View() string {
 	case len(m.filteredCommits) > 0:
 		c := m.filteredCommits[m.selectedIdx]
 		pct := m.viewport.ScrollPercent() * 100
+		keys := "q:quit /filter tab:switch j/k:nav gg/G:top/bot {/}:section"
+		if m.picking {
+			keys = "enter:pick " + keys
+		}
 		status = statusBarStyle.Render(fmt.Sprintf(
-			"%s %s  %.0f%%  [%d/%d commits]  q:quit /filter tab:switch j/k:nav gg/G:top/bot {/}:section",
-			c.Hash, c.Date, pct, m.selectedIdx+1, len(m.filteredCommits),
+			"%s %s  %.0f%%  [%d/%d commits]  %s",
+			c.Hash, c.Date, pct, m.selectedIdx+1, len(m.filteredCommits), keys,
 		))
 	default:
 		status = statusBarStyle.Render("No commits found")
@@ -3,6 +3,7 @@ package logui
 import (
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/madhermit/rift/internal/git"
 	"github.com/madhermit/rift/internal/tui"
 )
@@ -45,6 +46,19 @@ func TestCommitHeader(t *testing.T) {
 				"  A " + tui.FileIcon("README.md") + " README.md\n" +
 				"\n─────────────────────\n\n",
 		},
+		{
+			name: "with renamed file",
+			commit: git.CommitInfo{
+				Hash: "abc1234", Author: "Alice", Date: "2026-01-15 14:30",
+				Message: "Move code",
+			},
+			files: []git.ChangedFile{
+				{Path: "pkg/main.go", OldPath: "main.go", Status: "Renamed", Similarity: 100},
+			},
+			want: "commit abc1234\nAuthor: Alice\nDate:   2026-01-15 14:30\n\n    Move code\n\n" +
+				"  R " + tui.FileIcon("pkg/main.go") + " main.go → pkg/main.go\n" +
+				"\n─────────────────────\n\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -56,3 +70,29 @@ func TestCommitHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestPicker(t *testing.T) {
+	commits := []git.CommitInfo{
+		{Hash: "aaa1111", Message: "newest"},
+		{Hash: "bbb2222", Message: "older"},
+	}
+	var m tea.Model = NewPicker(nil, nil, commits, "rift fixup")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected enter to quit the picker")
+	}
+
+	got, ok := m.(Model).Picked()
+	if !ok || got.Hash != "bbb2222" {
+		t.Errorf("Picked() = %+v, %v, want bbb2222", got, ok)
+	}
+}
+
+func TestPicker_NotPickedOnQuit(t *testing.T) {
+	var m tea.Model = NewPicker(nil, nil, []git.CommitInfo{{Hash: "aaa1111"}}, "rift fixup")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if _, ok := m.(Model).Picked(); ok {
+		t.Error("Picked() reported a commit after quitting")
+	}
+}
@@ -50,7 +50,9 @@ func New() Model {
 		{Name: "branch", Description: "Fuzzy branch switcher", Available: true},
 		{Name: "stash", Description: "Stash manager with preview", Available: true},
 		{Name: "stage", Description: "Interactive hunk staging", Available: true},
-		{Name: "worktree", Description: "Worktree manager", Available: false},
+		{Name: "commit", Description: "Commit composer with staged diff preview", Available: true},
+		{Name: "rebase", Description: "Interactive rebase editor with commit preview", Available: true},
+		{Name: "worktree", Description: "Worktree manager", Available: true},
 	}
 
 	return Model{
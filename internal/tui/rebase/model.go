@@ -0,0 +1,357 @@
+package rebaseui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/madhermit/rift/internal/diff"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/tui"
+)
+
+type pane int
+
+const (
+	todoPane pane = iota
+	diffPane
+)
+
+// actionKeys maps the single-letter shortcuts git's todo format uses.
+var actionKeys = map[string]string{
+	"p": "pick",
+	"r": "reword",
+	"e": "edit",
+	"s": "squash",
+	"f": "fixup",
+	"d": "drop",
+}
+
+type Model struct {
+	repo   *git.Repo
+	engine diff.Engine
+
+	upstream    string
+	steps       []git.RebaseStep
+	selectedIdx int
+	activePane  pane
+
+	viewport    viewport.Model
+	vim         tui.VimNav
+	render      *tui.Render
+	diffContent string
+	diffErr     error
+	todoErr     error
+
+	confirmed bool
+
+	width  int
+	height int
+	ready  bool
+}
+
+type diffLoadedMsg struct {
+	hash    string
+	content string
+	err     error
+}
+
+type layout struct {
+	headerHeight  int
+	contentHeight int
+	listWidth     int
+	diffWidth     int
+}
+
+func (m Model) layout() layout {
+	l := layout{headerHeight: 3}
+	l.contentHeight = m.height - l.headerHeight
+
+	l.listWidth = m.width / 3
+	if l.listWidth < 36 {
+		l.listWidth = 36
+	}
+	if l.listWidth > 80 {
+		l.listWidth = 80
+	}
+	l.diffWidth = m.width - l.listWidth - 2 // diff pane border
+	if l.diffWidth < 10 {
+		l.diffWidth = 10
+	}
+	return l
+}
+
+// New builds the todo editor for rebasing onto upstream. steps is the
+// initial todo, oldest commit first.
+func New(repo *git.Repo, engine diff.Engine, upstream string, steps []git.RebaseStep) Model {
+	return Model{
+		repo:     repo,
+		engine:   engine,
+		upstream: upstream,
+		steps:    slices.Clone(steps),
+		viewport: viewport.New(0, 0),
+		render:   &tui.Render{},
+	}
+}
+
+// Confirmed reports whether the user asked to run the rebase.
+func (m Model) Confirmed() bool { return m.confirmed }
+
+// Steps returns the edited todo list.
+func (m Model) Steps() []git.RebaseStep { return m.steps }
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		return m.applyLayout()
+	case diffLoadedMsg:
+		if len(m.steps) == 0 || m.steps[m.selectedIdx].Hash != msg.hash {
+			return m, nil
+		}
+		m.diffErr = msg.err
+		m.diffContent = msg.content
+		m.setDiffContent()
+		m.viewport.GotoTop()
+		return m, nil
+	}
+
+	if m.activePane == diffPane {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.activePane == diffPane && m.vim.HandleKey(&m.viewport, msg) {
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+	case tea.KeyCtrlS:
+		return m.start()
+	case tea.KeyTab:
+		if m.activePane == todoPane {
+			m.activePane = diffPane
+		} else {
+			m.activePane = todoPane
+		}
+		return m, nil
+	case tea.KeyEnter:
+		if m.activePane == todoPane {
+			m.activePane = diffPane
+			return m, nil
+		}
+	case tea.KeyShiftUp:
+		return m.moveStep(-1)
+	case tea.KeyShiftDown:
+		return m.moveStep(1)
+	case tea.KeyUp:
+		if m.activePane == todoPane {
+			return m.moveSelection(-1)
+		}
+	case tea.KeyDown:
+		if m.activePane == todoPane {
+			return m.moveSelection(1)
+		}
+	case tea.KeySpace:
+		if m.activePane == todoPane {
+			return m.cycleAction()
+		}
+	case tea.KeyRunes:
+		key := string(msg.Runes)
+		if key == "q" {
+			return m, tea.Quit
+		}
+		if m.activePane != todoPane {
+			break
+		}
+		if action, ok := actionKeys[key]; ok {
+			return m.setAction(action)
+		}
+		switch key {
+		case "j":
+			return m.moveSelection(1)
+		case "k":
+			return m.moveSelection(-1)
+		case "J":
+			return m.moveStep(1)
+		case "K":
+			return m.moveStep(-1)
+		}
+	}
+
+	if m.activePane == diffPane {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) start() (tea.Model, tea.Cmd) {
+	if err := git.ValidateRebaseTodo(m.steps); err != nil {
+		m.todoErr = err
+		return m, nil
+	}
+	m.confirmed = true
+	return m, tea.Quit
+}
+
+func (m Model) setAction(action string) (tea.Model, tea.Cmd) {
+	if len(m.steps) == 0 {
+		return m, nil
+	}
+	m.steps = slices.Clone(m.steps)
+	m.steps[m.selectedIdx].Action = action
+	m.todoErr = nil
+	return m, nil
+}
+
+func (m Model) cycleAction() (tea.Model, tea.Cmd) {
+	if len(m.steps) == 0 {
+		return m, nil
+	}
+	i := slices.Index(git.RebaseActions, m.steps[m.selectedIdx].Action)
+	return m.setAction(git.RebaseActions[(i+1)%len(git.RebaseActions)])
+}
+
+// moveStep swaps the selected step with its neighbour, keeping it selected.
+func (m Model) moveStep(delta int) (tea.Model, tea.Cmd) {
+	j := m.selectedIdx + delta
+	if j < 0 || j >= len(m.steps) {
+		return m, nil
+	}
+	m.steps = slices.Clone(m.steps)
+	m.steps[m.selectedIdx], m.steps[j] = m.steps[j], m.steps[m.selectedIdx]
+	m.selectedIdx = j
+	m.todoErr = nil
+	return m, nil
+}
+
+func (m Model) moveSelection(delta int) (tea.Model, tea.Cmd) {
+	if len(m.steps) == 0 {
+		return m, nil
+	}
+	idx := min(max(m.selectedIdx+delta, 0), len(m.steps)-1)
+	if idx == m.selectedIdx {
+		return m, nil
+	}
+	m.selectedIdx = idx
+	return m, m.loadPreview()
+}
+
+func (m Model) applyLayout() (tea.Model, tea.Cmd) {
+	l := m.layout()
+	m.viewport.Width = l.diffWidth
+	m.viewport.Height = l.contentHeight - 2
+	m.setDiffContent()
+	return m, m.loadPreview()
+}
+
+func (m Model) loadPreview() tea.Cmd {
+	if len(m.steps) == 0 {
+		return nil
+	}
+	hash := m.steps[m.selectedIdx].Hash
+	width := m.viewport.Width
+	return m.render.Start(func(ctx context.Context) tea.Msg {
+		color := os.Getenv("NO_COLOR") == ""
+		content, err := m.engine.DiffCommit(ctx, m.repo.Root(), hash+"~1", hash, color, width)
+		return diffLoadedMsg{hash: hash, content: content, err: err}
+	})
+}
+
+func (m *Model) setDiffContent() {
+	content := m.diffContent
+	if w := m.viewport.Width; w > 0 && content != "" {
+		content = ansi.Hardwrap(content, w, true)
+	}
+	m.vim.SetContent(&m.viewport, content)
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	l := m.layout()
+
+	title := titleStyle.Render(fmt.Sprintf("rift rebase  [%s]  onto %s", m.engine.Name(), m.upstream))
+
+	// Todo list with scroll
+	var todo strings.Builder
+	listInnerHeight := l.contentHeight - 2
+	scrollOffset := 0
+	if m.selectedIdx >= listInnerHeight {
+		scrollOffset = m.selectedIdx - listInnerHeight + 1
+	}
+	for i := scrollOffset; i < len(m.steps) && i-scrollOffset < listInnerHeight; i++ {
+		s := m.steps[i]
+		action := actionStyles[s.Action].Render(fmt.Sprintf("%-6s", s.Action))
+		line := action + " " + truncate(s.Hash+" "+s.Message, l.listWidth-12)
+		if i == m.selectedIdx {
+			todo.WriteString(selectedStepStyle.Render(line))
+		} else {
+			todo.WriteString(stepItemStyle.Render(line))
+		}
+		todo.WriteString("\n")
+	}
+
+	listStyle, vpStyle := paneStyle, paneStyle
+	if m.activePane == todoPane {
+		listStyle = activePaneStyle
+	} else {
+		vpStyle = activePaneStyle
+	}
+	listPane := listStyle.Width(l.listWidth - 2).Height(l.contentHeight - 2).Render(todo.String())
+	diffPaneView := vpStyle.Width(l.diffWidth).Height(l.contentHeight - 2).Render(m.viewport.View())
+
+	content := lipgloss.JoinHorizontal(lipgloss.Top, listPane, diffPaneView)
+
+	// Status bar
+	var status string
+	switch {
+	case m.todoErr != nil:
+		status = errorStyle.Render(m.todoErr.Error())
+	case m.diffErr != nil:
+		status = statusBarStyle.Render(fmt.Sprintf("Error: %v", m.diffErr))
+	case len(m.steps) > 0:
+		status = statusBarStyle.Render(fmt.Sprintf(
+			"[%d/%d]  ctrl+s:rebase q:abort p/r/e/s/f/d:action space:cycle J/K:move tab:switch",
+			m.selectedIdx+1, len(m.steps),
+		))
+	default:
+		status = statusBarStyle.Render("Nothing to rebase")
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, content, status)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	if max <= 3 {
+		return s[:max]
+	}
+	return s[:max-3] + "..."
+}
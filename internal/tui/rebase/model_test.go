@@ -0,0 +1,56 @@
+package rebaseui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madhermit/rift/internal/git"
+)
+
+func press(m tea.Model, key string) tea.Model {
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return m
+}
+
+func TestTodoEditing(t *testing.T) {
+	steps := []git.RebaseStep{
+		{Action: "pick", Hash: "aaa1111", Message: "first"},
+		{Action: "pick", Hash: "bbb2222", Message: "second"},
+		{Action: "pick", Hash: "ccc3333", Message: "third"},
+	}
+	var m tea.Model = New(nil, nil, "origin/main", steps)
+
+	m = press(m, "j") // select second
+	m = press(m, "J") // move it below third
+	m = press(m, "f") // and make it a fixup
+
+	got := m.(Model).Steps()
+	want := []string{"pick aaa1111", "pick ccc3333", "fixup bbb2222"}
+	for i, s := range got {
+		if s.Action+" "+s.Hash != want[i] {
+			t.Errorf("step %d = %s %s, want %s", i, s.Action, s.Hash, want[i])
+		}
+	}
+	if steps[1].Hash != "bbb2222" {
+		t.Error("editing modified the caller's steps")
+	}
+}
+
+func TestStartRejectsLeadingSquash(t *testing.T) {
+	steps := []git.RebaseStep{
+		{Action: "pick", Hash: "aaa1111"},
+		{Action: "pick", Hash: "bbb2222"},
+	}
+	var m tea.Model = New(nil, nil, "origin/main", steps)
+	m = press(m, "s")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd != nil || m.(Model).Confirmed() {
+		t.Fatal("expected a squash with nothing before it to be rejected")
+	}
+
+	m = press(m, "p")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil || !m.(Model).Confirmed() {
+		t.Error("expected a valid todo to confirm and quit")
+	}
+}
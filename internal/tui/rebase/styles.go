@@ -0,0 +1,49 @@
+package rebaseui
+
+import "github.com/charmbracelet/lipgloss"
+
+var (
+	subtle = lipgloss.Color("241")
+	accent = lipgloss.Color("39")
+	white  = lipgloss.Color("15")
+
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(accent).
+			PaddingLeft(1)
+
+	statusBarStyle = lipgloss.NewStyle().
+			Foreground(subtle).
+			PaddingLeft(1)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("1")).
+			PaddingLeft(1)
+
+	stepItemStyle = lipgloss.NewStyle().
+			Foreground(subtle).
+			PaddingLeft(1)
+
+	selectedStepStyle = lipgloss.NewStyle().
+				Foreground(white).
+				Background(lipgloss.Color("236")).
+				PaddingLeft(1)
+
+	paneStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(subtle)
+
+	activePaneStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(accent)
+
+	// Todo action colors
+	actionStyles = map[string]lipgloss.Style{
+		"pick":   lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		"reword": lipgloss.NewStyle().Foreground(accent),
+		"edit":   lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		"squash": lipgloss.NewStyle().Foreground(lipgloss.Color("5")),
+		"fixup":  lipgloss.NewStyle().Foreground(lipgloss.Color("5")),
+		"drop":   lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Strikethrough(true),
+	}
+)
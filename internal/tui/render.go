@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Render runs one background render at a time: starting one cancels the one
+// before, and a cancelled render's result is dropped. Moving the cursor
+// through a long list then only pays for where it stops. Models hold it by
+// pointer, so every copy bubbletea makes shares it.
+type Render struct {
+	cancel context.CancelFunc
+}
+
+// Start cancels the render in flight and returns a command running render
+// with a fresh context. If the context is cancelled before render returns,
+// the command yields no message.
+func (r *Render) Start(render func(ctx context.Context) tea.Msg) tea.Cmd {
+	r.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	return func() tea.Msg {
+		msg := render(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return msg
+	}
+}
+
+// Stop cancels the render in flight, if any.
+func (r *Render) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
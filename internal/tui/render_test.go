@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRender_Start(t *testing.T) {
+	var r Render
+	started := make(chan context.Context, 1)
+	first := r.Start(func(ctx context.Context) tea.Msg {
+		started <- ctx
+		<-ctx.Done()
+		return "stale"
+	})
+	done := make(chan tea.Msg)
+	go func() { done <- first() }()
+	<-started
+
+	second := r.Start(func(context.Context) tea.Msg { return "fresh" })
+	if msg := <-done; msg != nil {
+		t.Errorf("superseded render yielded %v, want nothing", msg)
+	}
+	if msg := second(); msg != "fresh" {
+		t.Errorf("latest render yielded %v, want fresh", msg)
+	}
+}
@@ -36,6 +36,7 @@ type displayHunk struct {
 type Model struct {
 	repo   *git.Repo
 	engine diff.Engine
+	spec   git.Pathspec // limits the files listed, and kept on reload
 
 	files         []git.StatusFile
 	filteredFiles []git.StatusFile
@@ -46,12 +47,19 @@ type Model struct {
 	hunkOffsets  []int         // viewport line offset where each hunk starts
 	hunkIdx      int           // selected hunk index
 
+	lineMode   bool // visual line selection within the active hunk
+	lineCursor int  // cursor, as an index into the active hunk's Lines
+	lineAnchor int  // other end of the visual range
+	lineOffset int  // viewport line where the active hunk's lines start
+
 	viewport  viewport.Model
 	filter    textinput.Model
 	filtering bool
 
 	diffErr        error
 	vim            tui.VimNav
+	render         *tui.Render
+	confirm        tui.Confirm
 	skipDiffReload bool // after hunk stage/unstage, only reload file list
 
 	width  int
@@ -63,6 +71,14 @@ type hunkDiffsMsg struct {
 	hunks []displayHunk
 }
 
+type hunkSplitMsg struct {
+	path   string        // file the hunk belongs to
+	idx    int           // index of the hunk that was split
+	header string        // its header, and
+	staged bool          // its staged state, when the split started
+	hunks  []displayHunk // its sub-hunks, rendered
+}
+
 type filesLoadedMsg struct {
 	files []git.StatusFile
 	err   error
@@ -105,7 +121,7 @@ func (m Model) layout() layout {
 	return l
 }
 
-func New(repo *git.Repo, engine diff.Engine, files []git.StatusFile) Model {
+func New(repo *git.Repo, engine diff.Engine, files []git.StatusFile, spec git.Pathspec) Model {
 	filter := textinput.New()
 	filter.Prompt = "/ "
 	filter.PromptStyle = filterPromptStyle
@@ -114,10 +130,12 @@ func New(repo *git.Repo, engine diff.Engine, files []git.StatusFile) Model {
 	return Model{
 		repo:          repo,
 		engine:        engine,
+		spec:          spec,
 		files:         files,
 		filteredFiles: files,
 		viewport:      viewport.New(0, 0),
 		filter:        filter,
+		render:        &tui.Render{},
 	}
 }
 
@@ -136,6 +154,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.applyLayout()
 	case hunkDiffsMsg:
 		m.diffErr = nil
+		m.lineMode = false
 		m.displayHunks = msg.hunks
 		if m.hunkIdx >= len(m.displayHunks) {
 			m.hunkIdx = max(0, len(m.displayHunks)-1)
@@ -147,6 +166,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.GotoTop()
 		}
 		return m, nil
+	case hunkSplitMsg:
+		if msg.idx >= len(m.displayHunks) || len(m.filteredFiles) == 0 || m.filteredFiles[m.selectedIdx].Path != msg.path {
+			return m, nil
+		}
+		// Staging the hunk while it was being split changes it in place.
+		if dh := m.displayHunks[msg.idx]; dh.hunk.Header != msg.header || dh.staged != msg.staged {
+			return m, nil
+		}
+		hunks := make([]displayHunk, 0, len(m.displayHunks)+len(msg.hunks)-1)
+		hunks = append(hunks, m.displayHunks[:msg.idx]...)
+		hunks = append(hunks, msg.hunks...)
+		hunks = append(hunks, m.displayHunks[msg.idx+1:]...)
+		m.displayHunks = hunks
+		m.renderHunks()
+		if m.hunkIdx < len(m.hunkOffsets) {
+			m.viewport.SetYOffset(m.hunkOffsets[m.hunkIdx])
+		}
+		return m, nil
 	case filesLoadedMsg:
 		if msg.err != nil {
 			m.diffErr = msg.err
@@ -195,6 +232,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirm.Active() {
+		return m, m.confirm.HandleKey(msg)
+	}
+	if m.lineMode && !m.filtering {
+		return m.handleLineKey(msg)
+	}
 	if m.activePane == diffPane && !m.filtering {
 		if msg.Type == tea.KeyRunes {
 			switch string(msg.Runes) {
@@ -273,6 +316,16 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.activePane == diffPane {
 				return m.navigateHunk(-1)
 			}
+		case "v", "V":
+			if m.activePane == diffPane {
+				return m.enterLineMode()
+			}
+		case "S":
+			if m.activePane == diffPane {
+				return m, m.splitHunk()
+			}
+		case "d":
+			return m.discard()
 		}
 	}
 
@@ -304,6 +357,146 @@ func (m Model) navigateHunk(delta int) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// splitHunk breaks the active hunk into its independent change runs and
+// renders each one separately so they can be staged individually.
+func (m Model) splitHunk() tea.Cmd {
+	if m.hunkIdx >= len(m.displayHunks) {
+		return nil
+	}
+	dh := m.displayHunks[m.hunkIdx]
+	subs := diff.SplitHunk(dh.hunk)
+	if len(subs) < 2 {
+		return nil
+	}
+	path := m.filteredFiles[m.selectedIdx].Path
+	if dh.fd.Path != path {
+		// The selected file's hunks are still loading; starting the split
+		// would cancel that.
+		return nil
+	}
+	engine := m.engine
+	repoRoot := m.repo.Root()
+	width := m.viewport.Width
+	idx := m.hunkIdx
+	return m.render.Start(func(ctx context.Context) tea.Msg {
+		color := os.Getenv("NO_COLOR") == ""
+		base, _ := diff.BaseContent(repoRoot, dh.staged, path)
+		rendered := engine.DiffHunks(ctx, subs, path, base, color, width-2)
+		hunks := make([]displayHunk, len(subs))
+		for i, h := range subs {
+			hunks[i] = displayHunk{fd: dh.fd, hunk: h, rendered: rendered[i], staged: dh.staged}
+		}
+		return hunkSplitMsg{path: path, idx: idx, header: dh.hunk.Header, staged: dh.staged, hunks: hunks}
+	})
+}
+
+func (m Model) enterLineMode() (tea.Model, tea.Cmd) {
+	if m.hunkIdx >= len(m.displayHunks) {
+		return m, nil
+	}
+	m.lineMode = true
+	m.lineCursor = 0
+	for i, line := range m.displayHunks[m.hunkIdx].hunk.Lines {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			m.lineCursor = i
+			break
+		}
+	}
+	m.lineAnchor = m.lineCursor
+	m.renderHunks()
+	m.scrollToLineCursor()
+	return m, nil
+}
+
+func (m Model) handleLineKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		return m.exitLineMode()
+	case tea.KeyUp:
+		return m.moveLineCursor(-1)
+	case tea.KeyDown:
+		return m.moveLineCursor(1)
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "v", "V", "q":
+			return m.exitLineMode()
+		case "j":
+			return m.moveLineCursor(1)
+		case "k":
+			return m.moveLineCursor(-1)
+		case "s":
+			return m.stageLines(true)
+		case "u":
+			return m.stageLines(false)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) exitLineMode() (tea.Model, tea.Cmd) {
+	m.lineMode = false
+	m.renderHunks()
+	if m.hunkIdx < len(m.hunkOffsets) {
+		m.viewport.SetYOffset(m.hunkOffsets[m.hunkIdx])
+	}
+	return m, nil
+}
+
+func (m Model) moveLineCursor(delta int) (tea.Model, tea.Cmd) {
+	n := len(m.displayHunks[m.hunkIdx].hunk.Lines)
+	m.lineCursor = max(0, min(n-1, m.lineCursor+delta))
+	m.renderHunks()
+	m.scrollToLineCursor()
+	return m, nil
+}
+
+// selectedLines returns the visual range as indices into the hunk's Lines.
+func (m Model) selectedLines() map[int]bool {
+	lo, hi := min(m.lineAnchor, m.lineCursor), max(m.lineAnchor, m.lineCursor)
+	sel := make(map[int]bool, hi-lo+1)
+	for i := lo; i <= hi; i++ {
+		sel[i] = true
+	}
+	return sel
+}
+
+// stageLines stages or unstages only the selected lines of the active hunk
+// by synthesizing a partial patch from them.
+func (m Model) stageLines(stage bool) (tea.Model, tea.Cmd) {
+	dh := m.displayHunks[m.hunkIdx]
+	if dh.staged == stage {
+		return m, nil // already in desired state
+	}
+	partial, ok := dh.hunk.PartialHunk(m.selectedLines(), dh.staged)
+	if !ok {
+		return m, nil
+	}
+	m.lineMode = false
+	patch := partial.Patch(dh.fd.Header)
+	repo := m.repo
+	return m, func() tea.Msg {
+		var err error
+		if stage {
+			err = repo.StageHunk(patch)
+		} else {
+			err = repo.UnstageHunk(patch)
+		}
+		// The hunk now straddles index and worktree, so reload everything.
+		return stageResultMsg{err: err, hunkIdx: -1, staged: stage}
+	}
+}
+
+func (m *Model) scrollToLineCursor() {
+	off := m.lineOffset + m.lineCursor
+	if off < m.viewport.YOffset {
+		m.viewport.SetYOffset(off)
+	} else if off >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(off - m.viewport.Height + 1)
+	}
+}
+
 func (m Model) stageOrUnstage(stage bool) (tea.Model, tea.Cmd) {
 	if len(m.filteredFiles) == 0 {
 		return m, nil
@@ -353,6 +546,37 @@ func (m Model) stageOrUnstage(stage bool) (tea.Model, tea.Cmd) {
 	}
 }
 
+// discard asks before throwing away the unstaged changes to the selected file
+// or, in the diff pane, the selected unstaged hunk.
+func (m Model) discard() (tea.Model, tea.Cmd) {
+	if len(m.filteredFiles) == 0 {
+		return m, nil
+	}
+	f := m.filteredFiles[m.selectedIdx]
+	repo := m.repo
+	path := f.Path
+
+	if m.activePane == diffPane {
+		if m.hunkIdx >= len(m.displayHunks) || m.displayHunks[m.hunkIdx].staged {
+			return m, nil
+		}
+		dh := m.displayHunks[m.hunkIdx]
+		patch := dh.hunk.Patch(dh.fd.Header)
+		m.confirm.Ask(fmt.Sprintf("Discard hunk %d in %s?", m.hunkIdx+1, path), func() tea.Msg {
+			return stageResultMsg{err: repo.DiscardHunk(path, patch), hunkIdx: -1}
+		})
+		return m, nil
+	}
+
+	if f.WorktreeStatus == "" {
+		return m, nil
+	}
+	m.confirm.Ask(fmt.Sprintf("Discard unstaged changes to %s?", path), func() tea.Msg {
+		return stageResultMsg{err: repo.DiscardFile(path), hunkIdx: -1}
+	})
+	return m, nil
+}
+
 func (m Model) stageAll() (tea.Model, tea.Cmd) {
 	var paths []string
 	for _, f := range m.filteredFiles {
@@ -371,9 +595,9 @@ func (m Model) stageAll() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) reloadFiles() tea.Cmd {
-	repo := m.repo
+	repo, spec := m.repo, m.spec
 	return func() tea.Msg {
-		files, err := repo.StatusFiles()
+		files, err := repo.StatusFiles(spec)
 		if err != nil {
 			return filesLoadedMsg{err: err}
 		}
@@ -452,6 +676,7 @@ func (m Model) moveSelection(delta int) (tea.Model, tea.Cmd) {
 		m.selectedIdx = len(m.filteredFiles) - 1
 	}
 	m.hunkIdx = 0 // reset hunk selection on file change
+	m.lineMode = false
 	return m, m.loadSelectedDiff()
 }
 
@@ -463,7 +688,7 @@ func (m Model) loadSelectedDiff() tea.Cmd {
 	engine := m.engine
 	repoRoot := m.repo.Root()
 	width := m.viewport.Width
-	return func() tea.Msg {
+	return m.render.Start(func(ctx context.Context) tea.Msg {
 		untracked := f.StagingStatus == "Untracked" || f.WorktreeStatus == "Untracked"
 		color := os.Getenv("NO_COLOR") == ""
 		var result []displayHunk
@@ -472,30 +697,30 @@ func (m Model) loadSelectedDiff() tea.Cmd {
 			raw, err := diff.RawNewFileDiff(repoRoot, f.Path)
 			if err == nil && raw != "" {
 				result = append(result, buildDisplayHunks(
-					engine, raw, f.Path, repoRoot, false, color, width-2,
+					ctx, engine, raw, f.Path, repoRoot, false, color, width-2,
 				)...)
 			}
 		} else {
 			raw, err := diff.RawUnifiedDiff(repoRoot, false, f.Path)
 			if err == nil && raw != "" {
 				result = append(result, buildDisplayHunks(
-					engine, raw, f.Path, repoRoot, false, color, width-2,
+					ctx, engine, raw, f.Path, repoRoot, false, color, width-2,
 				)...)
 			}
 
 			raw, err = diff.RawUnifiedDiff(repoRoot, true, f.Path)
 			if err == nil && raw != "" {
 				result = append(result, buildDisplayHunks(
-					engine, raw, f.Path, repoRoot, true, color, width-2,
+					ctx, engine, raw, f.Path, repoRoot, true, color, width-2,
 				)...)
 			}
 		}
 
 		return hunkDiffsMsg{hunks: result}
-	}
+	})
 }
 
-func buildDisplayHunks(engine diff.Engine, raw, path, repoRoot string, staged, color bool, width int) []displayHunk {
+func buildDisplayHunks(ctx context.Context, engine diff.Engine, raw, path, repoRoot string, staged, color bool, width int) []displayHunk {
 	fileDiffs := diff.ParseUnifiedDiff(raw)
 	var allHunks []diff.Hunk
 	for _, fd := range fileDiffs {
@@ -505,7 +730,7 @@ func buildDisplayHunks(engine diff.Engine, raw, path, repoRoot string, staged, c
 		return nil
 	}
 	base, _ := diff.BaseContent(repoRoot, staged, path)
-	rendered := engine.DiffHunks(context.Background(), allHunks, path, base, color, width)
+	rendered := engine.DiffHunks(ctx, allHunks, path, base, color, width)
 
 	var result []displayHunk
 	flatIdx := 0
@@ -570,12 +795,19 @@ func (m *Model) renderHunks() {
 		b.WriteString("\n")
 		lineCount++
 
-		// Hunk content
-		content := dh.rendered
-		if innerW > 0 {
-			content = ansi.Hardwrap(content, innerW, true)
+		// Hunk content: raw lines while selecting, difftastic otherwise
+		var lines []string
+		if active && m.lineMode {
+			m.lineOffset = lineCount
+			lines = m.renderLines(dh.hunk, innerW)
+		} else {
+			content := dh.rendered
+			if innerW > 0 {
+				content = ansi.Hardwrap(content, innerW, true)
+			}
+			lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
 		}
-		for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		for _, line := range lines {
 			b.WriteString(sidebar)
 			b.WriteString(line)
 			b.WriteString("\n")
@@ -593,6 +825,33 @@ func (m *Model) renderHunks() {
 	m.vim.SetContent(&m.viewport, b.String())
 }
 
+// renderLines shows the raw lines of h, one viewport line each, with the
+// cursor and visual range marked. Lines are truncated rather than wrapped so
+// the cursor maps directly onto a viewport line.
+func (m Model) renderLines(h diff.Hunk, width int) []string {
+	sel := m.selectedLines()
+	out := make([]string, len(h.Lines))
+	for i, line := range h.Lines {
+		cursor := "  "
+		if i == m.lineCursor {
+			cursor = "▸ "
+		}
+		text := ansi.Truncate(cursor+line, width, "")
+		style := lipgloss.NewStyle()
+		switch {
+		case strings.HasPrefix(line, "+"):
+			style = lineAddedStyle
+		case strings.HasPrefix(line, "-"):
+			style = lineRemovedStyle
+		}
+		if sel[i] {
+			style = style.Inherit(lineSelectedStyle)
+		}
+		out[i] = style.Render(text)
+	}
+	return out
+}
+
 func (m Model) View() string {
 	if !m.ready {
 		return "Loading..."
@@ -652,13 +911,20 @@ func (m Model) View() string {
 	switch {
 	case m.filtering:
 		status = m.filter.View()
+	case m.confirm.Active():
+		status = confirmStyle.Render(m.confirm.Prompt())
 	case m.diffErr != nil:
 		status = statusBarStyle.Render(fmt.Sprintf("Error: %v", m.diffErr))
+	case m.lineMode:
+		lo, hi := min(m.lineAnchor, m.lineCursor), max(m.lineAnchor, m.lineCursor)
+		status = statusBarStyle.Render(fmt.Sprintf(
+			"-- LINES %d-%d --  j/k:extend s:stage u:unstage esc:cancel", lo+1, hi+1,
+		))
 	case len(m.filteredFiles) > 0:
 		f := m.filteredFiles[m.selectedIdx]
 		pct := m.viewport.ScrollPercent() * 100
 		status = statusBarStyle.Render(fmt.Sprintf(
-			"%s  %.0f%%  [%d/%d]  s:stage u:unstage a:all tab:switch n/p:hunk",
+			"%s  %.0f%%  [%d/%d]  s:stage u:unstage a:all tab:switch n/p:hunk S:split v:lines d:discard",
 			f.Path, pct, m.selectedIdx+1, len(m.filteredFiles),
 		))
 	default:
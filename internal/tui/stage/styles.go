@@ -27,6 +27,11 @@ var (
 				Background(lipgloss.Color("236")).
 				PaddingLeft(1)
 
+	confirmStyle = lipgloss.NewStyle().
+			Foreground(red).
+			Bold(true).
+			PaddingLeft(1)
+
 	filterPromptStyle = lipgloss.NewStyle().
 				Foreground(accent).
 				Bold(true)
@@ -61,4 +66,13 @@ var (
 			Render("▎") + " "
 
 	sidebarInactive = "  "
+
+	lineAddedStyle = lipgloss.NewStyle().
+			Foreground(green)
+
+	lineRemovedStyle = lipgloss.NewStyle().
+				Foreground(red)
+
+	lineSelectedStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("236"))
 )
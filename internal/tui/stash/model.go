@@ -49,6 +49,7 @@ type Model struct {
 	diffContent string
 	diffErr     error
 	vim         tui.VimNav
+	render      *tui.Render
 
 	action StashAction
 
@@ -106,6 +107,7 @@ func New(repo *git.Repo, engine diff.Engine, stashes []git.StashEntry) Model {
 		filteredStashes: stashes,
 		viewport:        viewport.New(0, 0),
 		filter:          filter,
+		render:          &tui.Render{},
 	}
 }
 
@@ -314,19 +316,19 @@ func (m Model) moveSelection(delta int) (tea.Model, tea.Cmd) {
 
 func (m Model) loadStashDiff(entry git.StashEntry) tea.Cmd {
 	width := m.viewport.Width
-	return func() tea.Msg {
+	return m.render.Start(func(ctx context.Context) tea.Msg {
 		ref := fmt.Sprintf("stash@{%d}", entry.Index)
 		base := ref + "^"
 		color := os.Getenv("NO_COLOR") == ""
 		content, err := m.engine.DiffCommit(
-			context.Background(), m.repo.Root(),
+			ctx, m.repo.Root(),
 			base, ref, color, width,
 		)
 		if err != nil {
 			return diffLoadedMsg{err: err}
 		}
 		return diffLoadedMsg{content: content}
-	}
+	})
 }
 
 func (m *Model) setDiffContent() {
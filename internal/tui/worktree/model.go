@@ -0,0 +1,445 @@
+package worktreeui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madhermit/rift/internal/git"
+	"github.com/madhermit/rift/internal/tui"
+	"github.com/sahilm/fuzzy"
+)
+
+const scrollMargin = 3
+
+type Model struct {
+	repo *git.Repo
+
+	worktrees   []git.WorktreeInfo
+	filtered    []git.WorktreeInfo
+	selectedIdx int
+	scrollOff   int
+	switchTo    string
+	picking     bool
+
+	filter    textinput.Model
+	filtering bool
+
+	branchInput textinput.Model
+	creating    bool
+
+	confirm tui.Confirm
+	message string
+	err     error
+
+	width  int
+	height int
+	ready  bool
+}
+
+type worktreesLoadedMsg struct {
+	worktrees []git.WorktreeInfo
+	message   string
+	err       error
+}
+
+// New builds the worktree manager.
+func New(repo *git.Repo, worktrees []git.WorktreeInfo) Model {
+	filter := textinput.New()
+	filter.Prompt = "/ "
+	filter.PromptStyle = filterPromptStyle
+	filter.CharLimit = 256
+
+	branchInput := textinput.New()
+	branchInput.Prompt = "branch: "
+	branchInput.PromptStyle = filterPromptStyle
+	branchInput.CharLimit = 256
+
+	return Model{
+		repo:        repo,
+		worktrees:   worktrees,
+		filtered:    worktrees,
+		filter:      filter,
+		branchInput: branchInput,
+	}
+}
+
+// NewPicker builds a read-only list where enter picks a worktree to switch
+// to and nothing can be added or removed.
+func NewPicker(worktrees []git.WorktreeInfo) Model {
+	m := New(nil, worktrees)
+	m.picking = true
+	return m
+}
+
+// SwitchTo returns the path of the worktree picked with enter, or "".
+func (m Model) SwitchTo() string {
+	return m.switchTo
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		m.clampScroll()
+		return m, nil
+	case worktreesLoadedMsg:
+		m.err = msg.err
+		m.message = msg.message
+		if msg.worktrees != nil {
+			m.worktrees = msg.worktrees
+			m.applyFilter()
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirm.Active() {
+		return m, m.confirm.HandleKey(msg)
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		switch {
+		case m.creating:
+			m.creating = false
+			m.branchInput.Blur()
+			m.branchInput.SetValue("")
+			return m, nil
+		case m.filtering:
+			m.filtering = false
+			m.filter.Blur()
+			m.filter.SetValue("")
+			m.applyFilter()
+			return m, nil
+		}
+		return m, tea.Quit
+	}
+
+	if m.creating {
+		return m.handleCreateKey(msg)
+	}
+	if m.filtering {
+		return m.handleFilterKey(msg)
+	}
+
+	m.message = ""
+	m.err = nil
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		if len(m.filtered) > 0 {
+			wt := m.filtered[m.selectedIdx]
+			if wt.Bare {
+				m.err = fmt.Errorf("the bare repository has no worktree to switch to")
+				return m, nil
+			}
+			m.switchTo = wt.Path
+			return m, tea.Quit
+		}
+	case tea.KeyUp:
+		m.moveSelection(-1)
+		return m, nil
+	case tea.KeyDown:
+		m.moveSelection(1)
+		return m, nil
+	case tea.KeyRunes:
+		key := string(msg.Runes)
+		switch key {
+		case "q":
+			return m, tea.Quit
+		case "/":
+			m.filtering = true
+			m.filter.Focus()
+			return m, nil
+		case "j":
+			m.moveSelection(1)
+			return m, nil
+		case "k":
+			m.moveSelection(-1)
+			return m, nil
+		}
+		if m.picking {
+			break
+		}
+		switch key {
+		case "d":
+			return m.remove(false)
+		case "D":
+			return m.remove(true)
+		case "p":
+			return m, m.prune()
+		case "n":
+			m.creating = true
+			m.branchInput.Focus()
+			return m, textinput.Blink
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyEnter {
+		m.filtering = false
+		m.filter.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.applyFilter()
+	return m, cmd
+}
+
+func (m Model) handleCreateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyEnter {
+		branch := strings.TrimSpace(m.branchInput.Value())
+		m.creating = false
+		m.branchInput.Blur()
+		m.branchInput.SetValue("")
+		if branch == "" {
+			return m, nil
+		}
+		return m, m.create(branch)
+	}
+
+	var cmd tea.Cmd
+	m.branchInput, cmd = m.branchInput.Update(msg)
+	return m, cmd
+}
+
+// create adds a worktree for branch at the default location, checking out
+// the branch if it exists and creating it from HEAD otherwise.
+func (m Model) create(branch string) tea.Cmd {
+	repo := m.repo
+	return func() tea.Msg {
+		path := repo.DefaultWorktreePath(branch)
+		if err := repo.AddWorktree(path, branch, !repo.BranchExists(branch), ""); err != nil {
+			return worktreesLoadedMsg{err: err}
+		}
+		return reload(repo, "Created "+path)
+	}
+}
+
+func (m Model) remove(force bool) (tea.Model, tea.Cmd) {
+	if len(m.filtered) == 0 {
+		return m, nil
+	}
+	wt := m.filtered[m.selectedIdx]
+	switch {
+	case wt.Bare:
+		m.err = fmt.Errorf("can't remove the bare repository")
+		return m, nil
+	case wt.Current:
+		m.err = fmt.Errorf("can't remove the current worktree")
+		return m, nil
+	}
+
+	repo := m.repo
+	prompt := fmt.Sprintf("Remove worktree %s?", wt.Path)
+	if force {
+		prompt = fmt.Sprintf("Force-remove worktree %s, discarding its changes?", wt.Path)
+	}
+	m.confirm.Ask(prompt, func() tea.Msg {
+		if err := repo.RemoveWorktree(wt.Path, force); err != nil {
+			return worktreesLoadedMsg{err: err}
+		}
+		return reload(repo, "Removed "+wt.Path)
+	})
+	return m, nil
+}
+
+func (m Model) prune() tea.Cmd {
+	repo := m.repo
+	return func() tea.Msg {
+		pruned, err := repo.PruneWorktrees()
+		if err != nil {
+			return worktreesLoadedMsg{err: err}
+		}
+		if len(pruned) == 0 {
+			return reload(repo, "Nothing to prune")
+		}
+		return reload(repo, fmt.Sprintf("Pruned %d stale worktree(s)", len(pruned)))
+	}
+}
+
+func reload(repo *git.Repo, message string) worktreesLoadedMsg {
+	worktrees, err := repo.ListWorktrees()
+	return worktreesLoadedMsg{worktrees: worktrees, message: message, err: err}
+}
+
+// label is what the filter matches against and the list shows first: the
+// branch, or the directory name for detached and bare entries.
+func label(wt git.WorktreeInfo) string {
+	if wt.Branch != "" {
+		return wt.Branch
+	}
+	return filepath.Base(wt.Path)
+}
+
+func (m *Model) applyFilter() {
+	// A reload after add/remove keeps the selection where it was; a new
+	// query starts from the top.
+	query := m.filter.Value()
+	if query == "" {
+		m.filtered = m.worktrees
+	} else {
+		labels := make([]string, len(m.worktrees))
+		for i, wt := range m.worktrees {
+			labels[i] = label(wt) + " " + wt.Path
+		}
+		matches := fuzzy.Find(query, labels)
+		filtered := make([]git.WorktreeInfo, len(matches))
+		for i, match := range matches {
+			filtered[i] = m.worktrees[match.Index]
+		}
+		m.filtered = filtered
+		m.selectedIdx = 0
+	}
+	m.selectedIdx = min(m.selectedIdx, max(len(m.filtered)-1, 0))
+	m.clampScroll()
+}
+
+func (m *Model) moveSelection(delta int) {
+	if len(m.filtered) == 0 {
+		return
+	}
+	m.selectedIdx = min(max(m.selectedIdx+delta, 0), len(m.filtered)-1)
+	m.clampScroll()
+}
+
+func (m *Model) clampScroll() {
+	visible := m.listHeight()
+
+	// Keep selection within scroll margin of the viewport edges
+	if m.selectedIdx < m.scrollOff+scrollMargin {
+		m.scrollOff = m.selectedIdx - scrollMargin
+	}
+	if m.selectedIdx >= m.scrollOff+visible-scrollMargin {
+		m.scrollOff = m.selectedIdx - visible + scrollMargin + 1
+	}
+
+	// Hard clamps
+	if m.scrollOff < 0 {
+		m.scrollOff = 0
+	}
+	if max := len(m.filtered) - visible; max > 0 && m.scrollOff > max {
+		m.scrollOff = max
+	}
+}
+
+func (m Model) listHeight() int {
+	h := m.height - 4 // title (with padding) + status + blank line
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	title := titleStyle.Render("rift worktree")
+	visible := m.listHeight()
+
+	labelWidth := 0
+	for _, wt := range m.filtered {
+		labelWidth = max(labelWidth, len(label(wt)))
+	}
+
+	var list strings.Builder
+	for i := m.scrollOff; i < len(m.filtered) && i-m.scrollOff < visible; i++ {
+		wt := m.filtered[i]
+		selected := i == m.selectedIdx
+
+		cursor := "  "
+		if selected {
+			cursor = "> "
+		}
+
+		prefix := "  "
+		if wt.Current {
+			prefix = "* "
+		}
+
+		head := wt.Head
+		if wt.Bare {
+			head = "(bare)"
+		}
+		line := fmt.Sprintf("%s%s%-*s  %-7s  %s", cursor, prefix, labelWidth, label(wt), head, wt.Path)
+
+		style := normalLineStyle
+		switch {
+		case selected:
+			style = selectedLineStyle
+		case wt.Current:
+			style = currentLineStyle
+		}
+		list.WriteString(style.Render(line) + flags(wt) + "\n")
+	}
+
+	// Scroll indicator
+	var scrollHint string
+	if len(m.filtered) > visible {
+		scrollHint = subtleStyle.Render(fmt.Sprintf(" (%d more)", len(m.filtered)-visible))
+	}
+
+	var status string
+	switch {
+	case m.confirm.Active():
+		status = confirmStyle.Render(m.confirm.Prompt())
+	case m.creating:
+		status = m.branchInput.View()
+	case m.filtering:
+		status = m.filter.View()
+	case m.err != nil:
+		status = errorStyle.Render(m.err.Error())
+	case m.message != "":
+		status = statusBarStyle.Render(m.message)
+	case len(m.filtered) > 0 && m.picking:
+		status = statusBarStyle.Render(fmt.Sprintf(
+			"[%d/%d]%s  q:quit  /:filter  j/k:nav  enter:pick",
+			m.selectedIdx+1, len(m.filtered), scrollHint,
+		))
+	case len(m.filtered) > 0:
+		status = statusBarStyle.Render(fmt.Sprintf(
+			"[%d/%d]%s  q:quit  /:filter  j/k:nav  enter:switch  n:new  d/D:remove/force  p:prune",
+			m.selectedIdx+1, len(m.filtered), scrollHint,
+		))
+	default:
+		status = statusBarStyle.Render("No worktrees found")
+	}
+
+	return title + "\n" + list.String() + status
+}
+
+func flags(wt git.WorktreeInfo) string {
+	var s string
+	if wt.Dirty {
+		s += "  " + dirtyStyle.Render("dirty")
+	}
+	if wt.Locked {
+		s += "  " + flagStyle.Render("locked")
+	}
+	if wt.Prunable {
+		s += "  " + flagStyle.Render("prunable")
+	}
+	return s
+}
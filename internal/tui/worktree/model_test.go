@@ -0,0 +1,67 @@
+package worktreeui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madhermit/rift/internal/git"
+)
+
+func press(m tea.Model, key string) tea.Model {
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return m
+}
+
+var worktrees = []git.WorktreeInfo{
+	{Path: "/src/proj/.bare", Bare: true},
+	{Path: "/src/proj/main", Branch: "main", Current: true},
+	{Path: "/src/proj/feat-x", Branch: "feat/x"},
+}
+
+func TestRemoveGuards(t *testing.T) {
+	var m tea.Model = New(nil, worktrees)
+
+	for i, name := range []string{"bare repo", "current worktree"} {
+		if i > 0 {
+			m = press(m, "j")
+		}
+		m = press(m, "d")
+		if m.(Model).confirm.Active() || m.(Model).err == nil {
+			t.Errorf("removing the %s should be refused", name)
+		}
+	}
+
+	m = press(m, "j")
+	m = press(m, "D")
+	if !m.(Model).confirm.Active() {
+		t.Fatal("expected a confirmation before force-removing feat/x")
+	}
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if cmd != nil || m.(Model).confirm.Active() {
+		t.Error("answering no should drop the removal")
+	}
+}
+
+func TestPickerSwitch(t *testing.T) {
+	var m tea.Model = NewPicker(worktrees)
+
+	m = press(m, "n")
+	if m.(Model).creating {
+		t.Error("the picker shouldn't offer to create worktrees")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.(Model).SwitchTo() != "" {
+		t.Error("picking the bare repo should be refused")
+	}
+
+	m = press(m, "/")
+	for _, r := range "feat" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // leave the filter
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.(Model).SwitchTo(); got != "/src/proj/feat-x" || cmd == nil {
+		t.Errorf("SwitchTo() = %q, want /src/proj/feat-x and quit", got)
+	}
+}
@@ -0,0 +1,53 @@
+package worktreeui
+
+import "github.com/charmbracelet/lipgloss"
+
+var (
+	subtle = lipgloss.Color("241")
+	accent = lipgloss.Color("39")
+	white  = lipgloss.Color("15")
+	green  = lipgloss.Color("35")
+	yellow = lipgloss.Color("214")
+	red    = lipgloss.Color("196")
+
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(accent).
+			PaddingLeft(1).
+			PaddingBottom(1)
+
+	statusBarStyle = lipgloss.NewStyle().
+			Foreground(subtle).
+			PaddingLeft(1)
+
+	confirmStyle = lipgloss.NewStyle().
+			Foreground(red).
+			Bold(true).
+			PaddingLeft(1)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("1")).
+			PaddingLeft(1)
+
+	filterPromptStyle = lipgloss.NewStyle().
+				Foreground(accent).
+				Bold(true)
+
+	normalLineStyle = lipgloss.NewStyle().
+			Foreground(subtle)
+
+	selectedLineStyle = lipgloss.NewStyle().
+				Foreground(white)
+
+	currentLineStyle = lipgloss.NewStyle().
+				Foreground(green)
+
+	dirtyStyle = lipgloss.NewStyle().
+			Foreground(yellow)
+
+	flagStyle = lipgloss.NewStyle().
+			Foreground(red)
+
+	subtleStyle = lipgloss.NewStyle().
+			Foreground(subtle)
+)